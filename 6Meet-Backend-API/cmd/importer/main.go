@@ -0,0 +1,26 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/dump"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/infrastructure"
+)
+
+func main() {
+	var opts dump.Options
+
+	flag.StringVar(&opts.XML, "xml", "", "pages-articles XML dump (.xml, .xml.gz or .xml.bz2)")
+	flag.StringVar(&opts.Page, "page", "", "page table SQL dump")
+	flag.StringVar(&opts.PageLinks, "pagelinks", "", "pagelinks table SQL dump")
+	flag.StringVar(&opts.Redirect, "redirect", "", "redirect table SQL dump (optional)")
+	flag.StringVar(&opts.LinkTarget, "linktarget", "", "linktarget table SQL dump (required by newer pagelinks dumps)")
+	output := flag.String("out", "storages/graph.jsonl", "output JSON lines file")
+	toMongo := flag.Bool("mongo", false, "write records to MongoDB instead of -out")
+	flag.Parse()
+
+	if err := infrastructure.RunImport(opts, *output, *toMongo); err != nil {
+		log.Fatalf("import failed: %v", err)
+	}
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/infrastructure"
+)
+
+func main() {
+	down := flag.Int("down", 0, "revert the given number of applied migrations")
+	status := flag.Bool("status", false, "list migrations and when they were applied")
+	flag.Parse()
+
+	if err := infrastructure.RunMigrate(*down, *status); err != nil {
+		log.Fatalf("migration failed: %v", err)
+	}
+}
@@ -0,0 +1,178 @@
+package db
+
+import (
+	"context"
+
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/database/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Migrations lists the schema migrations of the database. Versions are
+// never reused; new migrations are appended with the next version.
+func Migrations() []mongodb.Migration {
+	return []mongodb.Migration{
+		{
+			Version:     1,
+			Description: "merge users with duplicate names",
+			Up:          mergeDuplicateUsers,
+		},
+		mongodb.IndexMigration(2, "create user indexes", userCollection, userIndexes),
+		{
+			Version:     3,
+			Description: "store user neighbor counts",
+			Up:          backfillNeighborCounts,
+			Down:        dropNeighborCounts,
+		},
+		mongodb.IndexMigration(4, "index user neighbor counts", userCollection, userNeighborCountIndexes),
+		mongodb.IndexMigration(5, "index user deletion times", userCollection, userDeletedAtIndexes),
+		{
+			Version:     6,
+			Description: "scope unique user names to live users",
+			Up:          scopeUniqueNames,
+			Down:        unscopeUniqueNames,
+		},
+	}
+}
+
+// userLiveNameIndexes keep the names of live users unique. Live users have
+// no deletion time, so they share one; deleted users keep their name out of
+// the way of new users with it.
+var userLiveNameIndexes = []mongo.IndexModel{
+	{
+		Keys:    bson.D{{Key: "name", Value: 1}, {Key: "deleted_at", Value: 1}},
+		Options: options.Index().SetName("name_live_unique").SetUnique(true),
+	},
+}
+
+// scopeUniqueNames replaces the unique name index with one ignoring
+// deleted users. The new index is built first so names stay unique.
+func scopeUniqueNames(ctx context.Context, db *mongo.Database) error {
+	indexes := db.Collection(userCollection).Indexes()
+	if _, err := indexes.CreateMany(ctx, userLiveNameIndexes); err != nil {
+		return err
+	}
+	_, err := indexes.DropOne(ctx, "name_unique")
+	return err
+}
+
+// unscopeUniqueNames restores the unique name index over every user. It
+// fails while a deleted user shares its name with another user.
+func unscopeUniqueNames(ctx context.Context, db *mongo.Database) error {
+	indexes := db.Collection(userCollection).Indexes()
+	unique := mongo.IndexModel{
+		Keys:    bson.D{{Key: "name", Value: 1}},
+		Options: options.Index().SetName("name_unique").SetUnique(true),
+	}
+	if _, err := indexes.CreateOne(ctx, unique); err != nil {
+		return err
+	}
+	_, err := indexes.DropOne(ctx, "name_live_unique")
+	return err
+}
+
+// userDeletedAtIndexes find the deleted users to purge
+var userDeletedAtIndexes = []mongo.IndexModel{
+	{
+		Keys:    bson.D{{Key: "deleted_at", Value: 1}},
+		Options: options.Index().SetName("deleted_at").SetSparse(true),
+	},
+}
+
+// userNeighborCountIndexes sort users by their number of neighbors
+var userNeighborCountIndexes = []mongo.IndexModel{
+	{
+		Keys:    bson.D{{Key: "neighbor_count", Value: -1}, {Key: "_id", Value: -1}},
+		Options: options.Index().SetName("neighbor_count"),
+	},
+}
+
+// backfillNeighborCounts stores the length of the neighbors of every user
+func backfillNeighborCounts(ctx context.Context, db *mongo.Database) error {
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.D{
+			{Key: "neighbor_count", Value: bson.D{{Key: "$size", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$neighbors", bson.A{}}}}}}},
+		}}},
+	}
+
+	_, err := db.Collection(userCollection).UpdateMany(ctx, bson.M{}, update)
+	return err
+}
+
+// dropNeighborCounts removes the stored neighbor counts
+func dropNeighborCounts(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection(userCollection).UpdateMany(ctx, bson.M{}, bson.M{"$unset": bson.M{"neighbor_count": ""}})
+	return err
+}
+
+// duplicateUsers is a group of users sharing a name, oldest first
+type duplicateUsers struct {
+	Name      string               `bson:"_id"`
+	IDs       []primitive.ObjectID `bson:"ids"`
+	Aliases   [][]string           `bson:"aliases"`
+	Neighbors [][]string           `bson:"neighbors"`
+}
+
+// mergeDuplicateUsers keeps the oldest user of every name, merges the
+// aliases and neighbors of the others into it and deletes them, so the
+// unique name index can be built
+func mergeDuplicateUsers(ctx context.Context, db *mongo.Database) error {
+	collection := db.Collection(userCollection)
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$name"},
+			{Key: "ids", Value: bson.D{{Key: "$push", Value: "$_id"}}},
+			{Key: "aliases", Value: bson.D{{Key: "$push", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$aliases", bson.A{}}}}}}},
+			{Key: "neighbors", Value: bson.D{{Key: "$push", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$neighbors", bson.A{}}}}}}},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+		}}},
+		{{Key: "$match", Value: bson.D{{Key: "count", Value: bson.D{{Key: "$gt", Value: 1}}}}}},
+	}
+
+	cursor, err := collection.Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var group duplicateUsers
+		if err := cursor.Decode(&group); err != nil {
+			return err
+		}
+
+		update := bson.M{"$set": bson.M{
+			"aliases":   union(group.Aliases),
+			"neighbors": union(group.Neighbors),
+		}}
+		if _, err := collection.UpdateByID(ctx, group.IDs[0], update); err != nil {
+			return err
+		}
+
+		if _, err := collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": group.IDs[1:]}}); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+// union returns the distinct values of the lists in order of appearance
+func union(lists [][]string) []string {
+	values := []string{}
+	seen := make(map[string]bool)
+	for _, list := range lists {
+		for _, value := range list {
+			if !seen[value] {
+				seen[value] = true
+				values = append(values, value)
+			}
+		}
+	}
+
+	return values
+}
@@ -7,5 +7,7 @@ import (
 type User struct {
 	*mongodb.BaseModel `bson:",inline"`
 	Name               string   `json:"name" bson:"name"`
+	Aliases            []string `json:"aliases" bson:"aliases,omitempty"`
 	Neighbors          []string `json:"neighbors" bson:"neighbors"`
+	NeighborCount      int      `json:"neighbor_count" bson:"neighbor_count"` // len(Neighbors), kept so lists can skip the array
 }
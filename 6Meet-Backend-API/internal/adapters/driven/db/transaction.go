@@ -0,0 +1,13 @@
+package db
+
+import (
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/ports"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/database/mongodb"
+)
+
+var _ ports.UnitOfWork = (*mongodb.Client)(nil)
+
+// NewUnitOfWork creates a unit of work running transactions on the client
+func NewUnitOfWork(client *mongodb.Client) ports.UnitOfWork {
+	return client
+}
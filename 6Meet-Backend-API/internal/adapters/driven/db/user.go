@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"time"
 
 	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/adapters/driven/db/models"
 	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/core/entity"
@@ -9,14 +10,43 @@ import (
 	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/ports"
 	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/database/mongodb"
 	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/dto"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 const (
 	userCollection = "users"
 )
 
+// userQueryFields are the fields clients may filter users by
+var userQueryFields = []string{"_id", "name", "aliases", "neighbors", "neighbor_count", "created_at", "updated_at"}
+
+// userSortFields are the scalar fields clients may sort users by
+var userSortFields = []string{"_id", "name", "neighbor_count", "created_at", "updated_at"}
+
+// userIndexes are the indexes of the users collection: unique names, alias
+// lookups, the default newest-first sort and the stale page scan
+var userIndexes = []mongo.IndexModel{
+	{
+		Keys:    bson.D{{Key: "name", Value: 1}},
+		Options: options.Index().SetName("name_unique").SetUnique(true),
+	},
+	{
+		Keys:    bson.D{{Key: "aliases", Value: 1}},
+		Options: options.Index().SetName("aliases"),
+	},
+	{
+		Keys:    bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}},
+		Options: options.Index().SetName("created_at"),
+	},
+	{
+		Keys:    bson.D{{Key: "updated_at", Value: 1}, {Key: "_id", Value: 1}},
+		Options: options.Index().SetName("updated_at"),
+	},
+}
+
 type userRepository struct {
 	repo *mongodb.BaseRepository[models.User]
 }
@@ -27,7 +57,10 @@ var _ ports.UserRepository = (*userRepository)(nil)
 func NewUserRepository(db *mongo.Database) ports.UserRepository {
 	collection := db.Collection(userCollection)
 	return &userRepository{
-		repo: mongodb.NewBaseRepository[models.User](collection),
+		repo: mongodb.NewBaseRepository[models.User](collection,
+			mongodb.WithQueryFields(userQueryFields...),
+			mongodb.WithSortFields(userSortFields...),
+		),
 	}
 }
 
@@ -76,6 +109,81 @@ func (r *userRepository) Get(ctx context.Context, id string) (*entity.User, erro
 	return mapper.ToUserEntity(model), nil
 }
 
+// GetByName gets a user by its canonical name or one of its aliases
+func (r *userRepository) GetByName(ctx context.Context, name string) (*entity.User, error) {
+	filter := bson.M{
+		"$or": bson.A{
+			bson.M{"name": name},
+			bson.M{"aliases": name},
+		},
+	}
+
+	model, err := r.repo.FindOne(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// Map model -> entity
+	return mapper.ToUserEntity(model), nil
+}
+
+// FindByNames lists the users whose canonical name or one of whose aliases
+// is among the given names
+func (r *userRepository) FindByNames(ctx context.Context, names []string) ([]*entity.User, error) {
+	filter := bson.M{
+		"$or": bson.A{
+			bson.M{"name": bson.M{"$in": names}},
+			bson.M{"aliases": bson.M{"$in": names}},
+		},
+	}
+
+	models, err := r.repo.FindAll(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	entities := make([]*entity.User, len(models))
+	for i := range models {
+		entities[i] = mapper.ToUserEntity(&models[i])
+	}
+
+	return entities, nil
+}
+
+// FindStale lists users last updated before the given time, oldest first.
+// When after is set, only users ordered after it are returned so callers can
+// page through stale users without rewriting them.
+func (r *userRepository) FindStale(ctx context.Context, before time.Time, after *entity.User, limit int) ([]*entity.User, error) {
+	filter := bson.M{"updated_at": bson.M{"$lt": before}}
+
+	if after != nil {
+		afterID, err := primitive.ObjectIDFromHex(after.ID)
+		if err != nil {
+			return nil, err
+		}
+		filter["$or"] = bson.A{
+			bson.M{"updated_at": bson.M{"$gt": after.UpdatedAt}},
+			bson.M{"updated_at": after.UpdatedAt, "_id": bson.M{"$gt": afterID}},
+		}
+	}
+
+	findOpts := options.Find().
+		SetSort(bson.D{{Key: "updated_at", Value: 1}, {Key: "_id", Value: 1}}).
+		SetLimit(int64(limit))
+
+	models, err := r.repo.FindAll(ctx, filter, findOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	entities := make([]*entity.User, len(models))
+	for i := range models {
+		entities[i] = mapper.ToUserEntity(&models[i])
+	}
+
+	return entities, nil
+}
+
 // Create a new user
 func (r *userRepository) Create(ctx context.Context, user *entity.User) error {
 	// Map entity -> model
@@ -92,6 +200,57 @@ func (r *userRepository) Create(ctx context.Context, user *entity.User) error {
 	return nil
 }
 
+// UpsertMany writes users in bulk, returning one error per user. Users with
+// an ID are updated by ID, the others are matched by name and created when
+// no user has that name yet. Created users get their new ID.
+func (r *userRepository) UpsertMany(ctx context.Context, users []*entity.User) ([]error, error) {
+	errs := make([]error, len(users))
+
+	var byID, byName []int // positions in users of each upsert key
+	for i, user := range users {
+		if user.ID == "" {
+			byName = append(byName, i)
+			continue
+		}
+		if _, err := primitive.ObjectIDFromHex(user.ID); err != nil {
+			errs[i] = err
+			continue
+		}
+		byID = append(byID, i)
+	}
+
+	for _, group := range []struct {
+		key     string
+		indexes []int
+	}{
+		{key: "_id", indexes: byID},
+		{key: "name", indexes: byName},
+	} {
+		if len(group.indexes) == 0 {
+			continue
+		}
+
+		batch := make([]*models.User, len(group.indexes))
+		for j, i := range group.indexes {
+			batch[j] = mapper.ToUserModel(users[i])
+		}
+
+		results, err := r.repo.UpsertMany(ctx, group.key, batch)
+		if err != nil {
+			return nil, err
+		}
+
+		for j, i := range group.indexes {
+			errs[i] = results[j].Err
+			if results[j].Err == nil && !batch[j].ID.IsZero() {
+				users[i].ID = batch[j].ID.Hex()
+			}
+		}
+	}
+
+	return errs, nil
+}
+
 // Update user by ID
 func (r *userRepository) Update(ctx context.Context, id string, user *entity.User) error {
 	// Convert string ID to ObjectID
@@ -103,20 +262,72 @@ func (r *userRepository) Update(ctx context.Context, id string, user *entity.Use
 	// Map entity -> model
 	model := mapper.ToUserModel(user)
 
-	return r.repo.Update(ctx, oid, model)
+	if err := r.repo.Update(ctx, oid, model); err != nil {
+		return err
+	}
+
+	user.Version = model.Version
+	user.UpdatedAt = model.UpdatedAt
+
+	return nil
+}
+
+// Touch marks users as up to date without changing them
+func (r *userRepository) Touch(ctx context.Context, ids []string) error {
+	oids := make([]primitive.ObjectID, len(ids))
+	for i, id := range ids {
+		oid, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return err
+		}
+		oids[i] = oid
+	}
+
+	_, err := r.repo.Touch(ctx, oids)
+	return err
 }
 
-// Delete user by ID
-func (r *userRepository) Delete(ctx context.Context, id string) error {
+// Delete soft-deletes user by ID, only at the given version when one is set
+func (r *userRepository) Delete(ctx context.Context, id string, version *int64) error {
 	// Convert string ID to ObjectID
 	oid, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return err
 	}
 
+	if version != nil {
+		return r.repo.DeleteVersion(ctx, oid, *version)
+	}
 	return r.repo.Delete(ctx, oid)
 }
 
+// Restore brings back a soft-deleted user by ID
+func (r *userRepository) Restore(ctx context.Context, id string) error {
+	// Convert string ID to ObjectID
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	return r.repo.Restore(ctx, oid)
+}
+
+// Purge permanently removes a deleted user by ID
+func (r *userRepository) Purge(ctx context.Context, id string) error {
+	// Convert string ID to ObjectID
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	return r.repo.Purge(ctx, oid)
+}
+
+// PurgeDeleted permanently removes the users soft-deleted before the given time
+func (r *userRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	return r.repo.PurgeDeleted(ctx, before)
+}
+
 // Check if user exists by ID
 func (r *userRepository) Exists(ctx context.Context, id string) (bool, error) {
 	// Convert string ID to ObjectID
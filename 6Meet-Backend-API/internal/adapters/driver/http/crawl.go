@@ -0,0 +1,107 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/core/dto"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/ports"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/common/http/handler"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/common/http/request"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/common/http/response"
+)
+
+// CrawlHandler defines the interface for crawl job handler
+type CrawlHandler interface {
+	List(c *gin.Context)
+	Create(c *gin.Context)
+	Get(c *gin.Context)
+	Resize(c *gin.Context)
+	Cancel(c *gin.Context)
+}
+
+// crawlHandler implements CrawlHandler
+type crawlHandler struct {
+	handler.BaseHandler
+	crawlService ports.CrawlService
+}
+
+var _ CrawlHandler = (*crawlHandler)(nil)
+
+func NewCrawlHandler(crawlService ports.CrawlService) CrawlHandler {
+	return &crawlHandler{
+		crawlService: crawlService,
+	}
+}
+
+// List handles the HTTP request to list crawl jobs
+func (h *crawlHandler) List(c *gin.Context) {
+	jobs, err := h.crawlService.List(c.Request.Context())
+	if err != nil {
+		response.ErrorResponse(c, response.CodeInternalServer, err)
+		return
+	}
+
+	response.SuccessResponse(c, response.CodeRetrieved, jobs)
+}
+
+// Get handles the HTTP request to get the progress of a crawl job
+func (h *crawlHandler) Get(c *gin.Context) {
+	id := c.Param("id")
+
+	job, err := h.crawlService.Get(c.Request.Context(), id)
+	if err != nil {
+		response.ErrorResponse(c, response.CodeInternalServer, err)
+		return
+	}
+
+	response.SuccessResponse(c, response.CodeRetrieved, job)
+}
+
+// Create handles the HTTP request to start a crawl job
+func (h *crawlHandler) Create(c *gin.Context) {
+	req, ok := request.ParseRequest[dto.CreateCrawlRequest](c)
+
+	if !ok {
+		return
+	}
+
+	job, err := h.crawlService.Create(c.Request.Context(), req)
+	if err != nil {
+		response.ErrorResponse(c, response.CodeInternalServer, err)
+		return
+	}
+
+	response.SuccessResponse(c, response.CodeCreated, job)
+}
+
+// Resize handles the HTTP request to change the concurrency of a crawl job
+func (h *crawlHandler) Resize(c *gin.Context) {
+	req, ok := request.ParseRequest[dto.ResizeCrawlRequest](c)
+
+	if !ok {
+		return
+	}
+
+	id := c.Param("id")
+
+	job, err := h.crawlService.Resize(c.Request.Context(), id, req)
+	if err != nil {
+		response.ErrorResponse(c, response.CodeInternalServer, err)
+		return
+	}
+
+	response.SuccessResponse(c, response.CodeUpdated, job)
+}
+
+// Cancel handles the HTTP request to cancel a crawl job
+func (h *crawlHandler) Cancel(c *gin.Context) {
+	id := c.Param("id")
+
+	job, err := h.crawlService.Cancel(c.Request.Context(), id)
+	if err != nil {
+		response.ErrorResponse(c, response.CodeInternalServer, err)
+		return
+	}
+
+	response.SuccessResponse(c, response.CodeDeleted, job)
+}
@@ -1,6 +1,11 @@
 package http
 
 import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
 	"github.com/gin-gonic/gin"
 
 	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/core/dto"
@@ -17,8 +22,11 @@ type UserHandler interface {
 	Find(c *gin.Context)
 	Create(c *gin.Context)
 	Get(c *gin.Context)
+	GetByName(c *gin.Context)
 	Update(c *gin.Context)
 	Delete(c *gin.Context)
+	Restore(c *gin.Context)
+	Purge(c *gin.Context)
 }
 
 // userHandler implements UserHandler
@@ -62,6 +70,25 @@ func (h *userHandler) Get(c *gin.Context) {
 		return
 	}
 
+	if notModified(c, user) {
+		return
+	}
+	response.SuccessResponse(c, response.CodeRetrieved, user)
+}
+
+// GetByName handles the HTTP request to get a user by name or alias
+func (h *userHandler) GetByName(c *gin.Context) {
+	name := c.Param("name")
+
+	user, err := h.userService.GetByName(c.Request.Context(), name)
+	if err != nil {
+		response.ErrorResponse(c, response.CodeInternalServer, err)
+		return
+	}
+
+	if notModified(c, user) {
+		return
+	}
 	response.SuccessResponse(c, response.CodeRetrieved, user)
 }
 
@@ -79,6 +106,7 @@ func (h *userHandler) Create(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", userETag(user))
 	response.SuccessResponse(c, response.CodeCreated, user)
 }
 
@@ -92,20 +120,36 @@ func (h *userHandler) Update(c *gin.Context) {
 
 	id := c.Param("id")
 
+	// If-Match takes precedence over the version in the body
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+	if version != nil {
+		req.Version = version
+	}
+
 	user, err := h.userService.Update(c.Request.Context(), id, req)
 	if err != nil {
 		response.ErrorResponse(c, response.CodeInternalServer, err)
 		return
 	}
 
+	c.Header("ETag", userETag(user))
 	response.SuccessResponse(c, response.CodeUpdated, user)
 }
 
-// Delete handles the HTTP request to delete a user by ID
+// Delete handles the HTTP request to delete a user by ID, only at the
+// version given by If-Match when the header is set
 func (h *userHandler) Delete(c *gin.Context) {
 	id := c.Param("id")
 
-	err := h.userService.Delete(c.Request.Context(), id)
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	err := h.userService.Delete(c.Request.Context(), id, version)
 	if err != nil {
 		response.ErrorResponse(c, response.CodeInternalServer, err)
 		return
@@ -113,3 +157,79 @@ func (h *userHandler) Delete(c *gin.Context) {
 
 	response.SuccessResponse(c, response.CodeDeleted, nil)
 }
+
+// Restore handles the HTTP request to restore a deleted user by ID
+func (h *userHandler) Restore(c *gin.Context) {
+	id := c.Param("id")
+
+	user, err := h.userService.Restore(c.Request.Context(), id)
+	if err != nil {
+		response.ErrorResponse(c, response.CodeInternalServer, err)
+		return
+	}
+
+	c.Header("ETag", userETag(user))
+	response.SuccessResponse(c, response.CodeUpdated, user)
+}
+
+// Purge handles the HTTP request to permanently remove a user by ID
+func (h *userHandler) Purge(c *gin.Context) {
+	id := c.Param("id")
+
+	err := h.userService.Purge(c.Request.Context(), id)
+	if err != nil {
+		response.ErrorResponse(c, response.CodeInternalServer, err)
+		return
+	}
+
+	response.SuccessResponse(c, response.CodeDeleted, nil)
+}
+
+// userETag is the entity tag of a user version
+func userETag(user *dto.UserResponse) string {
+	return fmt.Sprintf(`"%d"`, user.Version)
+}
+
+// ifMatchVersion returns the user version required by the If-Match header,
+// nil when any version matches. A malformed header is answered with 400.
+func ifMatchVersion(c *gin.Context) (*int64, bool) {
+	ifMatch := c.GetHeader("If-Match")
+	if ifMatch == "" || ifMatch == "*" {
+		return nil, true
+	}
+
+	version, err := parseUserETag(ifMatch)
+	if err != nil {
+		response.ErrorResponse(c, response.CodeBadRequest, err)
+		return nil, false
+	}
+	return &version, true
+}
+
+// parseUserETag returns the version of a user entity tag
+func parseUserETag(tag string) (int64, error) {
+	value := strings.Trim(strings.TrimPrefix(strings.TrimSpace(tag), "W/"), `"`)
+
+	version, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid If-Match header %q", tag)
+	}
+
+	return version, nil
+}
+
+// notModified sets the ETag of a user and answers 304 when the client's
+// If-None-Match already names it
+func notModified(c *gin.Context, user *dto.UserResponse) bool {
+	etag := userETag(user)
+	c.Header("ETag", etag)
+
+	for _, tag := range strings.Split(c.GetHeader("If-None-Match"), ",") {
+		if tag = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(tag), "W/")); tag == etag || tag == "*" {
+			c.Status(http.StatusNotModified)
+			return true
+		}
+	}
+
+	return false
+}
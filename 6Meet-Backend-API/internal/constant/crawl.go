@@ -0,0 +1,5 @@
+package constant
+
+const (
+	CrawlSeedDir = "storages" // seed files of crawl jobs must live here
+)
@@ -0,0 +1,24 @@
+// Package cache keeps the cached copies of users in sync with the
+// database for writers outside the user service, e.g. the crawler and the
+// dump importer.
+package cache
+
+import (
+	"context"
+
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/global"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/constant"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/utils"
+	"go.uber.org/zap"
+)
+
+// InvalidateUsers drops the cached copies of changed users, so their next
+// read returns the new version. Failures are logged; a stale copy expires
+// with the cache TTL.
+func InvalidateUsers(ctx context.Context, ids ...string) {
+	for _, id := range ids {
+		if err := utils.HandleDeleteCache(ctx, global.Redis, constant.PrefixUser+id); err != nil {
+			global.Logger.Error("Failed to invalidate cache", zap.String("id", id), zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package dto
+
+import "time"
+
+type CreateCrawlRequest struct {
+	Seeds       []string `json:"seeds" validate:"omitempty,dive,required"`
+	File        string   `json:"file" validate:"omitempty"`
+	Depth       int      `json:"depth" validate:"min=0,max=5"`
+	Concurrency int      `json:"concurrency" validate:"min=0,max=256"`
+}
+
+type ResizeCrawlRequest struct {
+	Concurrency int `json:"concurrency" validate:"required,min=1,max=256"`
+}
+
+type WorkerPoolStatsResponse struct {
+	State        string  `json:"state"`
+	Workers      int     `json:"workers"`
+	Active       int     `json:"active"`
+	Queued       int     `json:"queued"`
+	Completed    int64   `json:"completed"`
+	Failed       int64   `json:"failed"`
+	AvgLatencyMs float64 `json:"avg_latency_ms"`
+	P50LatencyMs float64 `json:"p50_latency_ms"`
+	P95LatencyMs float64 `json:"p95_latency_ms"`
+	P99LatencyMs float64 `json:"p99_latency_ms"`
+}
+
+type CrawlJobResponse struct {
+	ID             string                   `json:"id"`
+	Status         string                   `json:"status"`
+	Error          string                   `json:"error,omitempty"`
+	Seeds          int                      `json:"seeds"`
+	File           string                   `json:"file,omitempty"`
+	Depth          int                      `json:"depth"`
+	Concurrency    int                      `json:"concurrency"`
+	Queued         int64                    `json:"queued"`
+	Done           int64                    `json:"done"`
+	Failed         int64                    `json:"failed"`
+	PagesPerSecond float64                  `json:"pages_per_second"`
+	ETASeconds     float64                  `json:"eta_seconds"`
+	Workers        *WorkerPoolStatsResponse `json:"workers,omitempty"`
+	CreatedAt      time.Time                `json:"created_at"`
+	FinishedAt     *time.Time               `json:"finished_at,omitempty"`
+}
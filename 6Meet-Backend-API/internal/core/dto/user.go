@@ -2,16 +2,34 @@ package dto
 
 type CreateUserRequest struct {
 	Name      string   `json:"name" validate:"required"`
+	Aliases   []string `json:"aliases" validate:"omitempty,dive,required"`
 	Neighbors []string `json:"neighbors" validate:"min=1,dive,required"`
 }
 
 type UpdateUserRequest struct {
 	Name      *string   `json:"name" validate:"omitempty"`
+	Aliases   *[]string `json:"aliases" validate:"omitempty,dive,required"`
 	Neighbors *[]string `json:"neighbors" validate:"omitempty,min=1,dive,required"`
+	Version   *int64    `json:"version,omitempty"` // expected version, also taken from If-Match
 }
 
+// UserResponse is a user as returned by the API
 type UserResponse struct {
-	ID        string   `json:"id"`
-	Name      string   `json:"name"`
-	Neighbors []string `json:"neighbors"`
+	ID            string   `json:"id"`
+	Name          string   `json:"name"`
+	Aliases       []string `json:"aliases"`
+	Neighbors     []string `json:"neighbors"`
+	NeighborCount int      `json:"neighbor_count"`
+	Version       int64    `json:"version"`
+}
+
+// UserFieldsResponse is a user in a list. Without a sparse fieldset it has
+// the shape of UserResponse; fields left out of a fieldset are omitted.
+type UserFieldsResponse struct {
+	ID            string    `json:"id"`
+	Name          *string   `json:"name,omitempty"`
+	Aliases       *[]string `json:"aliases,omitempty"`
+	Neighbors     *[]string `json:"neighbors,omitempty"`
+	NeighborCount *int      `json:"neighbor_count,omitempty"`
+	Version       int64     `json:"version"`
 }
@@ -1,12 +1,34 @@
 package entity
 
-import "time"
+import (
+	"slices"
+	"time"
+)
 
 // User represents the domain entity for a user
 type User struct {
 	ID        string    `json:"id"`
 	Name      string    `json:"name"`
+	Aliases   []string  `json:"aliases"`
 	Neighbors []string  `json:"neighbors"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	Version   int64     `json:"version"`
+
+	NeighborCount int `json:"neighbor_count"` // stored count, known even when Neighbors was not loaded
+}
+
+// MergeTitles sets the canonical name of the user. The previous name and
+// the given aliases are kept as aliases so lookups by old titles still work.
+func (u *User) MergeTitles(name string, aliases []string) {
+	if u.Name != "" && u.Name != name {
+		aliases = append([]string{u.Name}, aliases...)
+	}
+	u.Name = name
+
+	for _, alias := range aliases {
+		if alias != name && !slices.Contains(u.Aliases, alias) {
+			u.Aliases = append(u.Aliases, alias)
+		}
+	}
 }
@@ -0,0 +1,65 @@
+package mapper
+
+import (
+	"time"
+
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/core/dto"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/crawl"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/common/goroutine"
+)
+
+// ToCrawlJobResponse converts a crawl job snapshot to Response DTO
+func ToCrawlJobResponse(s crawl.JobSnapshot) *dto.CrawlJobResponse {
+	return &dto.CrawlJobResponse{
+		ID:             s.ID,
+		Status:         string(s.Status),
+		Error:          s.Error,
+		Seeds:          len(s.Options.Seeds),
+		File:           s.Options.File,
+		Depth:          s.Options.Depth,
+		Concurrency:    s.Options.Concurrency,
+		Queued:         s.Queued,
+		Done:           s.Done,
+		Failed:         s.Failed,
+		PagesPerSecond: s.PagesPerSecond,
+		ETASeconds:     s.ETA.Seconds(),
+		Workers:        ToWorkerPoolStatsResponse(s.Pool),
+		CreatedAt:      s.CreatedAt,
+		FinishedAt:     s.FinishedAt,
+	}
+}
+
+// ToCrawlJobOptions converts Request DTO to crawl job options
+func ToCrawlJobOptions(req *dto.CreateCrawlRequest) crawl.JobOptions {
+	return crawl.JobOptions{
+		Seeds:       req.Seeds,
+		File:        req.File,
+		Depth:       req.Depth,
+		Concurrency: req.Concurrency,
+	}
+}
+
+// ToWorkerPoolStatsResponse converts worker pool statistics to Response DTO
+func ToWorkerPoolStatsResponse(s *goroutine.Stats) *dto.WorkerPoolStatsResponse {
+	if s == nil {
+		return nil
+	}
+
+	return &dto.WorkerPoolStatsResponse{
+		State:        s.State,
+		Workers:      s.Workers,
+		Active:       s.Active,
+		Queued:       s.Queued,
+		Completed:    s.Completed,
+		Failed:       s.Failed,
+		AvgLatencyMs: toMilliseconds(s.AvgLatency),
+		P50LatencyMs: toMilliseconds(s.P50Latency),
+		P95LatencyMs: toMilliseconds(s.P95Latency),
+		P99LatencyMs: toMilliseconds(s.P99Latency),
+	}
+}
+
+// toMilliseconds converts a duration to fractional milliseconds
+func toMilliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -1,6 +1,7 @@
 package mapper
 
 import (
+	"slices"
 	"time"
 
 	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/adapters/driven/db/models"
@@ -14,11 +15,14 @@ import (
 // ToUserEntity converts DB Model to Domain Entity
 func ToUserEntity(m *models.User) *entity.User {
 	return &entity.User{
-		ID:        m.BaseModel.ID.Hex(),
-		Name:      m.Name,
-		Neighbors: m.Neighbors,
-		CreatedAt: m.BaseModel.CreatedAt,
-		UpdatedAt: m.BaseModel.UpdatedAt,
+		ID:            m.BaseModel.ID.Hex(),
+		Name:          m.Name,
+		Aliases:       m.Aliases,
+		Neighbors:     m.Neighbors,
+		CreatedAt:     m.BaseModel.CreatedAt,
+		UpdatedAt:     m.BaseModel.UpdatedAt,
+		Version:       m.BaseModel.Version,
+		NeighborCount: m.NeighborCount,
 	}
 }
 
@@ -31,25 +35,65 @@ func ToUserModel(e *entity.User) *models.User {
 			ID:        id,
 			CreatedAt: e.CreatedAt,
 			UpdatedAt: e.UpdatedAt,
+			Version:   e.Version,
 		},
-		Name:      e.Name,
-		Neighbors: e.Neighbors,
+		Name:          e.Name,
+		Aliases:       e.Aliases,
+		Neighbors:     e.Neighbors,
+		NeighborCount: len(e.Neighbors),
 	}
 }
 
 // ToUserResponse converts Domain Entity to Response DTO
 func ToUserResponse(e *entity.User) *dto.UserResponse {
+	neighborCount := e.NeighborCount
+	if e.Neighbors != nil {
+		neighborCount = len(e.Neighbors)
+	}
+
 	return &dto.UserResponse{
-		ID:        e.ID,
-		Name:      e.Name,
-		Neighbors: e.Neighbors,
+		ID:            e.ID,
+		Name:          e.Name,
+		Aliases:       e.Aliases,
+		Neighbors:     e.Neighbors,
+		NeighborCount: neighborCount,
+		Version:       e.Version,
+	}
+}
+
+// ToUserResponseFields converts Domain Entity to a list Response DTO keeping
+// only the requested fields, or every field when none are requested
+func ToUserResponseFields(e *entity.User, fields []string) *dto.UserFieldsResponse {
+	full := ToUserResponse(e)
+	resp := &dto.UserFieldsResponse{
+		ID:      full.ID,
+		Version: full.Version,
+	}
+
+	selected := func(field string) bool {
+		return len(fields) == 0 || slices.Contains(fields, field)
+	}
+	if selected("name") {
+		resp.Name = &full.Name
+	}
+	if selected("aliases") {
+		resp.Aliases = &full.Aliases
 	}
+	if selected("neighbors") {
+		resp.Neighbors = &full.Neighbors
+	}
+	if selected("neighbor_count") {
+		resp.NeighborCount = &full.NeighborCount
+	}
+
+	return resp
 }
 
 // ToUserEntityFromReq converts Request DTO to Domain Entity
 func ToUserEntityFromReq(req *dto.CreateUserRequest) *entity.User {
 	return &entity.User{
 		Name:      req.Name,
+		Aliases:   req.Aliases,
 		Neighbors: req.Neighbors,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
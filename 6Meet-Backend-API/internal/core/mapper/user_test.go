@@ -0,0 +1,46 @@
+package mapper
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/core/entity"
+)
+
+func TestUserResponseShape(t *testing.T) {
+	user := &entity.User{ID: "u1", Neighbors: []string{"a", "b"}, Version: 3}
+
+	tests := []struct {
+		name   string
+		value  any
+		expect string
+	}{
+		{
+			name:   "full response keeps empty fields",
+			value:  ToUserResponse(user),
+			expect: `{"id":"u1","name":"","aliases":null,"neighbors":["a","b"],"neighbor_count":2,"version":3}`,
+		},
+		{
+			name:   "list without fieldset",
+			value:  ToUserResponseFields(user, nil),
+			expect: `{"id":"u1","name":"","aliases":null,"neighbors":["a","b"],"neighbor_count":2,"version":3}`,
+		},
+		{
+			name:   "sparse fieldset",
+			value:  ToUserResponseFields(&entity.User{ID: "u1", NeighborCount: 7}, []string{"name", "neighbor_count"}),
+			expect: `{"id":"u1","name":"","neighbor_count":7,"version":0}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.expect {
+				t.Errorf("JSON = %s; want %s", data, tt.expect)
+			}
+		})
+	}
+}
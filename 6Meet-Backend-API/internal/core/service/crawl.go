@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/constant"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/core/cache"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/core/dto"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/core/entity"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/core/mapper"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/crawl"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/ports"
+
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/common/apperr"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/common/http/response"
+)
+
+type crawlService struct {
+	manager  *crawl.Manager
+	userRepo ports.UserRepository
+}
+
+var _ ports.CrawlService = (*crawlService)(nil)
+
+// NewCrawlService creates the crawl job service. Running jobs are cancelled when ctx is done.
+func NewCrawlService(
+	ctx context.Context,
+	userRepo ports.UserRepository,
+) ports.CrawlService {
+	s := &crawlService{
+		userRepo: userRepo,
+	}
+	s.manager = crawl.NewManager(ctx, s.storeBatch)
+
+	return s
+}
+
+// List lists all crawl jobs, newest first
+func (s *crawlService) List(ctx context.Context) ([]*dto.CrawlJobResponse, error) {
+	jobs := s.manager.List()
+
+	responses := make([]*dto.CrawlJobResponse, len(jobs))
+	for i, job := range jobs {
+		responses[i] = mapper.ToCrawlJobResponse(job.Snapshot())
+	}
+
+	return responses, nil
+}
+
+// Get gets the progress of a crawl job
+func (s *crawlService) Get(ctx context.Context, id string) (*dto.CrawlJobResponse, error) {
+	job, err := s.manager.Get(id)
+	if err != nil {
+		return nil, apperr.New(response.CodeNotFound, "Crawl job not found", http.StatusNotFound, err)
+	}
+
+	return mapper.ToCrawlJobResponse(job.Snapshot()), nil
+}
+
+// Create starts a new crawl job in the background
+func (s *crawlService) Create(ctx context.Context, req *dto.CreateCrawlRequest) (*dto.CrawlJobResponse, error) {
+	opts := mapper.ToCrawlJobOptions(req)
+
+	if opts.File != "" {
+		file, err := seedFilePath(opts.File)
+		if err != nil {
+			return nil, apperr.New(response.CodeBadRequest, "Invalid seed file", http.StatusBadRequest, err)
+		}
+		opts.File = file
+	}
+
+	job, err := s.manager.Start(opts)
+	if err != nil {
+		if errors.Is(err, crawl.ErrNoSeeds) {
+			return nil, apperr.New(response.CodeBadRequest, "Seeds or file is required", http.StatusBadRequest, err)
+		}
+		if errors.Is(err, crawl.ErrTooManyJobs) {
+			return nil, apperr.New(response.CodeConflict, "Too many crawl jobs running", http.StatusConflict, err)
+		}
+		return nil, apperr.Wrap(err, response.CodeInternalServer, "Failed to start crawl job", http.StatusInternalServerError)
+	}
+
+	return mapper.ToCrawlJobResponse(job.Snapshot()), nil
+}
+
+// Resize changes the concurrency of a running crawl job
+func (s *crawlService) Resize(ctx context.Context, id string, req *dto.ResizeCrawlRequest) (*dto.CrawlJobResponse, error) {
+	job, err := s.manager.Resize(id, req.Concurrency)
+	if err != nil {
+		if errors.Is(err, crawl.ErrJobNotFound) {
+			return nil, apperr.New(response.CodeNotFound, "Crawl job not found", http.StatusNotFound, err)
+		}
+		return nil, apperr.Wrap(err, response.CodeInternalServer, "Failed to resize crawl job", http.StatusInternalServerError)
+	}
+
+	return mapper.ToCrawlJobResponse(job.Snapshot()), nil
+}
+
+// Cancel cancels a running crawl job
+func (s *crawlService) Cancel(ctx context.Context, id string) (*dto.CrawlJobResponse, error) {
+	job, err := s.manager.Cancel(id)
+	if err != nil {
+		return nil, apperr.New(response.CodeNotFound, "Crawl job not found", http.StatusNotFound, err)
+	}
+
+	return mapper.ToCrawlJobResponse(job.Snapshot()), nil
+}
+
+// storeBatch creates or updates the users of a batch of crawled pages in
+// bulk, returning one error per page. Pages whose title is the name or an
+// alias of a stored user update that user.
+func (s *crawlService) storeBatch(ctx context.Context, records []*dto.CreateUserRequest) []error {
+	names := make([]string, len(records))
+	for i, record := range records {
+		names[i] = record.Name
+	}
+
+	existing, err := s.userRepo.FindByNames(ctx, names)
+	if err != nil {
+		return batchError(len(records), err)
+	}
+
+	users := make([]*entity.User, len(records))
+	for i, record := range records {
+		user := findByTitle(existing, record.Name)
+		if user == nil {
+			users[i] = mapper.ToUserEntityFromReq(record)
+			continue
+		}
+
+		user.MergeTitles(record.Name, record.Aliases)
+		user.Neighbors = record.Neighbors
+		users[i] = user
+	}
+
+	errs, err := s.userRepo.UpsertMany(ctx, users)
+	if err != nil {
+		return batchError(len(records), err)
+	}
+	cache.InvalidateUsers(ctx, writtenIDs(users, errs)...)
+
+	return errs
+}
+
+// writtenIDs returns the IDs of the users a bulk write stored
+func writtenIDs(users []*entity.User, errs []error) []string {
+	ids := make([]string, 0, len(users))
+	for i, user := range users {
+		if errs[i] == nil && user.ID != "" {
+			ids = append(ids, user.ID)
+		}
+	}
+	return ids
+}
+
+// findByTitle returns the user named title, or else the user having title
+// as an alias
+func findByTitle(users []*entity.User, title string) *entity.User {
+	var aliased *entity.User
+	for _, user := range users {
+		if user.Name == title {
+			return user
+		}
+		if aliased == nil && slices.Contains(user.Aliases, title) {
+			aliased = user
+		}
+	}
+
+	return aliased
+}
+
+// batchError reports the same error for every record of a batch
+func batchError(n int, err error) []error {
+	errs := make([]error, n)
+	for i := range errs {
+		errs[i] = err
+	}
+
+	return errs
+}
+
+// seedFilePath resolves a seed file name inside the seed directory
+func seedFilePath(file string) (string, error) {
+	path := filepath.Join(constant.CrawlSeedDir, file)
+	if !strings.HasPrefix(path, constant.CrawlSeedDir+string(filepath.Separator)) {
+		return "", fmt.Errorf("seed file %q is outside %s", file, constant.CrawlSeedDir)
+	}
+
+	return path, nil
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/global"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/ports"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/settings"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/utils"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultPurgeInterval  = 3600           // seconds between purge runs
+	defaultPurgeRetainFor = 30 * 24 * 3600 // seconds a deleted user can be restored
+)
+
+// Purger permanently removes users that stayed deleted past the retention period
+type Purger struct {
+	userRepo  ports.UserRepository
+	interval  time.Duration
+	retainFor time.Duration
+}
+
+// NewPurger creates a purger of soft-deleted users
+func NewPurger(userRepo ports.UserRepository, config settings.Purge) *Purger {
+	if config.Interval <= 0 {
+		config.Interval = defaultPurgeInterval
+	}
+	if config.RetainFor <= 0 {
+		config.RetainFor = defaultPurgeRetainFor
+	}
+
+	return &Purger{
+		userRepo:  userRepo,
+		interval:  utils.ToDuration(config.Interval),
+		retainFor: utils.ToDuration(config.RetainFor),
+	}
+}
+
+// Run purges expired users periodically until ctx is done
+func (p *Purger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := p.PurgeOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			global.Logger.Error("Failed to purge deleted users", zap.Error(err))
+		}
+		if purged > 0 {
+			global.Logger.Info("Purged deleted users", zap.Int64("users", purged))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PurgeOnce removes the users deleted longer ago than the retention period
+func (p *Purger) PurgeOnce(ctx context.Context) (int64, error) {
+	return p.userRepo.PurgeDeleted(ctx, time.Now().Add(-p.retainFor))
+}
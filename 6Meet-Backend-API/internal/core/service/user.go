@@ -2,59 +2,73 @@ package service
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/huynhanx03/6Meet/6Meet-Backend-API/global"
 	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/constant"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/core/cache"
 	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/core/dto"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/core/entity"
 	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/core/mapper"
 	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/ports"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.uber.org/zap"
 
 	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/common/apperr"
 	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/common/http/response"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/database/mongodb"
 	d "github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/dto"
 	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/utils"
 )
 
 type userService struct {
 	userRepo ports.UserRepository
+	uow      ports.UnitOfWork
 }
 
 var _ ports.UserService = (*userService)(nil)
 
 func NewUserService(
 	userRepo ports.UserRepository,
+	uow ports.UnitOfWork,
 ) ports.UserService {
 	return &userService{
 		userRepo: userRepo,
+		uow:      uow,
 	}
 }
 
 // Find lists users with pagination and sorting
-func (s *userService) Find(ctx context.Context, opts *d.QueryOptions) (*d.Paginated[*dto.UserResponse], error) {
+func (s *userService) Find(ctx context.Context, opts *d.QueryOptions) (*d.Paginated[*dto.UserFieldsResponse], error) {
 	// Query database
 	users, err := s.userRepo.Find(ctx, opts)
+	if errors.Is(err, d.ErrInvalidCursor) {
+		return nil, apperr.New(response.CodeBadRequest, "Invalid cursor", http.StatusBadRequest, err)
+	}
+	if errors.Is(err, d.ErrInvalidFilter) {
+		return nil, apperr.New(response.CodeBadRequest, err.Error(), http.StatusBadRequest, err)
+	}
 	if err != nil {
 		return nil, apperr.Wrap(err, response.CodeDatabaseError, "Failed to list users", http.StatusInternalServerError)
 	}
 
 	if users.Records == nil {
-		return &d.Paginated[*dto.UserResponse]{
-			Records:    &[]*dto.UserResponse{},
+		return &d.Paginated[*dto.UserFieldsResponse]{
+			Records:    &[]*dto.UserFieldsResponse{},
 			Pagination: users.Pagination,
 		}, nil
 	}
 
 	// Map generic result from models -> entity
 	userEntities := *users.Records
-	userResponses := make([]*dto.UserResponse, len(userEntities))
+	userResponses := make([]*dto.UserFieldsResponse, len(userEntities))
 	for i, user := range userEntities {
-		userResponses[i] = mapper.ToUserResponse(user)
+		userResponses[i] = mapper.ToUserResponseFields(user, opts.Fields)
 	}
 
-	return &d.Paginated[*dto.UserResponse]{
+	return &d.Paginated[*dto.UserFieldsResponse]{
 		Records:    &userResponses,
 		Pagination: users.Pagination,
 	}, nil
@@ -92,6 +106,22 @@ func (s *userService) Get(ctx context.Context, id string) (*dto.UserResponse, er
 	return &response, nil
 }
 
+// GetByName gets a user by name, resolving redirects and alternative titles
+func (s *userService) GetByName(ctx context.Context, name string) (*dto.UserResponse, error) {
+	user, err := s.userRepo.GetByName(ctx, utils.NormalizeTitle(name))
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, apperr.New(response.CodeNotFound, "User not found", http.StatusNotFound, err)
+		}
+		return nil, apperr.Wrap(err, response.CodeInternalServer, "Failed to get user", http.StatusInternalServerError)
+	}
+
+	// Map entity -> response
+	userResponse := *mapper.ToUserResponse(user)
+
+	return &userResponse, nil
+}
+
 // Create a new user
 func (s *userService) Create(ctx context.Context, req *dto.CreateUserRequest) (*dto.UserResponse, error) {
 	// Mapper RequestDTO -> Entity
@@ -99,6 +129,9 @@ func (s *userService) Create(ctx context.Context, req *dto.CreateUserRequest) (*
 
 	// Create user
 	if err := s.userRepo.Create(ctx, user); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, errUserNameTaken(err)
+		}
 		return nil, apperr.Wrap(err, response.CodeDatabaseError, "Failed to create user", http.StatusInternalServerError)
 	}
 
@@ -110,23 +143,51 @@ func (s *userService) Create(ctx context.Context, req *dto.CreateUserRequest) (*
 
 // Update an existing user
 func (s *userService) Update(ctx context.Context, id string, req *dto.UpdateUserRequest) (*dto.UserResponse, error) {
-	// Check existence
-	user, err := s.userRepo.Get(ctx, id)
-	if err != nil {
-		return nil, apperr.New(response.CodeNotFound, "User not found", http.StatusNotFound, err)
-	}
+	var user *entity.User
 
-	// Update user
-	if req.Name != nil {
-		user.Name = *req.Name
-	}
-	if req.Neighbors != nil {
-		user.Neighbors = *req.Neighbors
-	}
+	err := s.uow.WithTransaction(ctx, func(ctx context.Context) error {
+		// Check existence
+		var err error
+		user, err = s.userRepo.Get(ctx, id)
+		if err != nil {
+			return apperr.New(response.CodeNotFound, "User not found", http.StatusNotFound, err)
+		}
+
+		// Check the version the client edited
+		if req.Version != nil && *req.Version != user.Version {
+			return errUserConflict(mongodb.ErrVersionConflict)
+		}
+
+		// Update user
+		if req.Name != nil {
+			user.Name = *req.Name
+		}
+		if req.Aliases != nil {
+			user.Aliases = *req.Aliases
+		}
+		if req.Neighbors != nil {
+			user.Neighbors = *req.Neighbors
+		}
+
+		if err := s.userRepo.Update(ctx, id, user); err != nil {
+			if errors.Is(err, mongodb.ErrVersionConflict) {
+				return errUserConflict(err)
+			}
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return apperr.New(response.CodeNotFound, "User not found", http.StatusNotFound, err)
+			}
+			if mongo.IsDuplicateKeyError(err) {
+				return errUserNameTaken(err)
+			}
+			return apperr.Wrap(err, response.CodeDatabaseError, "Failed to update user", http.StatusInternalServerError)
+		}
 
-	if err := s.userRepo.Update(ctx, id, user); err != nil {
-		return nil, apperr.Wrap(err, response.CodeDatabaseError, "Failed to update user", http.StatusInternalServerError)
+		return nil
+	})
+	if err != nil {
+		return nil, appError(err, "Failed to update user")
 	}
+	s.invalidateCache(ctx, id)
 
 	// Map entity -> response
 	userResponse := *mapper.ToUserResponse(user)
@@ -134,8 +195,10 @@ func (s *userService) Update(ctx context.Context, id string, req *dto.UpdateUser
 	return &userResponse, nil
 }
 
-// Delete an existing user
-func (s *userService) Delete(ctx context.Context, id string) error {
+// Delete soft-deletes an existing user, which can be restored until it is
+// purged. With a version, the user is only deleted if it is still at that
+// version.
+func (s *userService) Delete(ctx context.Context, id string, version *int64) error {
 	// Check existence
 	exists, err := s.userRepo.Exists(ctx, id)
 	if err != nil {
@@ -145,8 +208,91 @@ func (s *userService) Delete(ctx context.Context, id string) error {
 		return apperr.New(response.CodeNotFound, "User not found", http.StatusNotFound, nil)
 	}
 
-	if err := s.userRepo.Delete(ctx, id); err != nil {
+	if err := s.userRepo.Delete(ctx, id, version); err != nil {
+		if errors.Is(err, mongodb.ErrVersionConflict) {
+			return errUserConflict(err)
+		}
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return apperr.New(response.CodeNotFound, "User not found", http.StatusNotFound, err)
+		}
 		return apperr.Wrap(err, response.CodeDatabaseError, "Failed to delete user", http.StatusInternalServerError)
 	}
+	s.invalidateCache(ctx, id)
+
 	return nil
 }
+
+// Restore brings back a deleted user
+func (s *userService) Restore(ctx context.Context, id string) (*dto.UserResponse, error) {
+	var user *entity.User
+
+	err := s.uow.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.Restore(ctx, id); err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return apperr.New(response.CodeNotFound, "Deleted user not found", http.StatusNotFound, err)
+			}
+			if mongo.IsDuplicateKeyError(err) {
+				return errUserNameTaken(err)
+			}
+			return apperr.Wrap(err, response.CodeDatabaseError, "Failed to restore user", http.StatusInternalServerError)
+		}
+
+		var err error
+		user, err = s.userRepo.Get(ctx, id)
+		if err != nil {
+			return apperr.Wrap(err, response.CodeInternalServer, "Failed to get user", http.StatusInternalServerError)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, appError(err, "Failed to restore user")
+	}
+	s.invalidateCache(ctx, id)
+
+	// Map entity -> response
+	userResponse := *mapper.ToUserResponse(user)
+
+	return &userResponse, nil
+}
+
+// Purge permanently removes a deleted user. Live users must be deleted first.
+func (s *userService) Purge(ctx context.Context, id string) error {
+	if err := s.userRepo.Purge(ctx, id); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return apperr.New(response.CodeNotFound, "User not found", http.StatusNotFound, err)
+		}
+		if errors.Is(err, mongodb.ErrNotDeleted) {
+			return apperr.New(response.CodeConflict, "User must be deleted before it is purged", http.StatusConflict, err)
+		}
+		return apperr.Wrap(err, response.CodeDatabaseError, "Failed to purge user", http.StatusInternalServerError)
+	}
+	s.invalidateCache(ctx, id)
+
+	return nil
+}
+
+// invalidateCache drops the cached copy of a changed user
+func (s *userService) invalidateCache(ctx context.Context, id string) {
+	cache.InvalidateUsers(ctx, id)
+}
+
+// appError returns the AppError of a failed transaction, or wraps an error
+// raised by the transaction itself, e.g. when its commit failed
+func appError(err error, message string) error {
+	var appErr *apperr.AppError
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return apperr.Wrap(err, response.CodeDatabaseError, message, http.StatusInternalServerError)
+}
+
+// errUserConflict reports an update based on an outdated version of a user
+func errUserConflict(err error) error {
+	return apperr.New(response.CodeConflict, "User was modified by someone else, reload it and retry", http.StatusConflict, err)
+}
+
+// errUserNameTaken reports a write giving a user the name of another live user
+func errUserNameTaken(err error) error {
+	return apperr.New(response.CodeConflict, "A user with this name already exists", http.StatusConflict, err)
+}
@@ -0,0 +1,131 @@
+package crawl
+
+import (
+	"errors"
+	"runtime"
+	"time"
+
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/global"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/common/goroutine"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/settings"
+)
+
+const (
+	defaultUserAgent     = "6MeetBot/1.0"
+	defaultRateLimit     = 10
+	defaultRateBurst     = 10
+	defaultMaxLag        = 5
+	defaultMaxRetries    = 3
+	defaultMaxRetryAfter = 60
+	defaultCacheTTL      = 86400    // 1 Day
+	defaultCacheMaxBytes = 64 << 20 // 64 MiB
+	defaultCacheDiskMax  = 1 << 30  // 1 GiB
+
+	defaultBreakerFailureRatio   = 0.5
+	defaultBreakerMinRequests    = 20
+	defaultBreakerWindow         = 60 // 1 Minute
+	defaultBreakerCooldown       = 30
+	defaultBreakerHalfOpenProbes = 1
+
+	defaultCassettePath = "storages/cassettes/wikipedia.jsonl"
+
+	pageTimeout = 5 * time.Minute // time budget of a single page fetch attempt
+
+	storeBatchSize  = 200             // crawled pages written per batch
+	storeMaxLatency = 2 * time.Second // longest a crawled page waits to be written
+
+	defaultRefreshInterval   = 3600   // 1 Hour
+	defaultRefreshStaleAfter = 604800 // 7 Days
+	defaultRefreshBatchSize  = 500
+
+	defaultJobRetention   = 86400 // 1 Day
+	defaultJobMaxFinished = 100
+	defaultJobMaxRunning  = 4
+)
+
+// pageConcurrency is the default number of concurrent page fetches. Fetches
+// are IO-bound, so it matches the sizing of goroutine.NewIOExecutor.
+var pageConcurrency = 4 * runtime.GOMAXPROCS(0)
+
+// pageRetryPolicy retries failed pages once, except pages that do not exist
+var pageRetryPolicy = goroutine.RetryPolicy{
+	MaxAttempts: 2,
+	Backoff:     goroutine.ExponentialBackoff(5*time.Second, time.Minute),
+	Retryable: func(err error) bool {
+		return !errors.Is(err, ErrPageMissing)
+	},
+}
+
+// crawlerConfig returns the crawler configuration with defaults applied
+func crawlerConfig() settings.Crawler {
+	var config settings.Crawler
+	if global.Config != nil {
+		config = global.Config.Crawler
+	}
+
+	if config.UserAgent == "" {
+		config.UserAgent = defaultUserAgent
+	}
+	if config.RateLimit == 0 {
+		config.RateLimit = defaultRateLimit
+	}
+	if config.RateBurst == 0 {
+		config.RateBurst = defaultRateBurst
+	}
+	if config.MaxLag == 0 {
+		config.MaxLag = defaultMaxLag
+	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = defaultMaxRetries
+	}
+	if config.MaxRetryAfter == 0 {
+		config.MaxRetryAfter = defaultMaxRetryAfter
+	}
+	if config.Cache.TTL == 0 {
+		config.Cache.TTL = defaultCacheTTL
+	}
+	if config.Cache.MaxBytes == 0 {
+		config.Cache.MaxBytes = defaultCacheMaxBytes
+	}
+	if config.Cache.DiskMaxBytes == 0 {
+		config.Cache.DiskMaxBytes = defaultCacheDiskMax
+	}
+	if config.Breaker.FailureRatio == 0 {
+		config.Breaker.FailureRatio = defaultBreakerFailureRatio
+	}
+	if config.Breaker.MinRequests == 0 {
+		config.Breaker.MinRequests = defaultBreakerMinRequests
+	}
+	if config.Breaker.Window == 0 {
+		config.Breaker.Window = defaultBreakerWindow
+	}
+	if config.Breaker.Cooldown == 0 {
+		config.Breaker.Cooldown = defaultBreakerCooldown
+	}
+	if config.Breaker.HalfOpenProbes == 0 {
+		config.Breaker.HalfOpenProbes = defaultBreakerHalfOpenProbes
+	}
+	if config.Cassette.Path == "" {
+		config.Cassette.Path = defaultCassettePath
+	}
+	if config.Refresh.Interval == 0 {
+		config.Refresh.Interval = defaultRefreshInterval
+	}
+	if config.Refresh.StaleAfter == 0 {
+		config.Refresh.StaleAfter = defaultRefreshStaleAfter
+	}
+	if config.Refresh.BatchSize == 0 {
+		config.Refresh.BatchSize = defaultRefreshBatchSize
+	}
+	if config.Jobs.Retention == 0 {
+		config.Jobs.Retention = defaultJobRetention
+	}
+	if config.Jobs.MaxFinished == 0 {
+		config.Jobs.MaxFinished = defaultJobMaxFinished
+	}
+	if config.Jobs.MaxRunning == 0 {
+		config.Jobs.MaxRunning = defaultJobMaxRunning
+	}
+
+	return config
+}
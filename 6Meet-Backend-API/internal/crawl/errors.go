@@ -0,0 +1,31 @@
+package crawl
+
+import (
+	"errors"
+
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/global"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/common/goroutine"
+	"go.uber.org/zap"
+)
+
+// logTaskError logs a failed crawl task together with the page that caused
+// it and, for panics, the recovered value and stack trace
+func logTaskError(msg string, err error, fields ...zap.Field) {
+	var taskErr *goroutine.TaskError
+	if errors.As(err, &taskErr) {
+		fields = append(fields,
+			zap.String("page", taskErr.TaskID),
+			zap.Int("attempts", taskErr.Attempts),
+		)
+	}
+
+	var panicErr *goroutine.PanicError
+	if errors.As(err, &panicErr) {
+		fields = append(fields,
+			zap.Any("panic", panicErr.Value),
+			zap.ByteString("stack", panicErr.Stack),
+		)
+	}
+
+	global.Logger.Error(msg, append(fields, zap.Error(err))...)
+}
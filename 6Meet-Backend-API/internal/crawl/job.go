@@ -0,0 +1,517 @@
+package crawl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/global"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/core/dto"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/common/goroutine"
+	commonHttp "github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/common/http"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/common/pipeline"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/settings"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+// JobStatus is the lifecycle state of a crawl job
+type JobStatus string
+
+const (
+	JobRunning   JobStatus = "running"
+	JobCompleted JobStatus = "completed"
+	JobFailed    JobStatus = "failed"
+	JobCancelled JobStatus = "cancelled"
+)
+
+var (
+	// ErrJobNotFound is returned when no job has the requested ID
+	ErrJobNotFound = errors.New("crawl job not found")
+
+	// ErrNoSeeds is returned when a job has nothing to crawl
+	ErrNoSeeds = errors.New("crawl job has no seed pages")
+
+	// ErrTooManyJobs is returned when the maximum number of jobs is running
+	ErrTooManyJobs = errors.New("too many crawl jobs running")
+)
+
+// ResultHandler stores the pages crawled by a job in batches. It returns nil
+// when every record was stored, or one error per record.
+type ResultHandler = goroutine.FlushFunc[*dto.CreateUserRequest]
+
+// JobOptions configures a crawl job
+type JobOptions struct {
+	Seeds       []string // seed page titles
+	File        string   // file with one seed title per line
+	Depth       int      // link hops to follow from the seeds, 0 crawls only the seeds
+	Concurrency int      // concurrent page fetches, 0 uses the IO executor default
+}
+
+// JobSnapshot is a point-in-time view of a crawl job
+type JobSnapshot struct {
+	ID             string
+	Options        JobOptions
+	Status         JobStatus
+	Error          string
+	Queued         int64 // pages waiting to be crawled
+	Done           int64 // pages crawled successfully
+	Failed         int64 // pages that could not be crawled
+	PagesPerSecond float64
+	ETA            time.Duration    // estimated time to drain the current queue, 0 if unknown
+	Pool           *goroutine.Stats // worker pool of the level being crawled, nil when idle
+	CreatedAt      time.Time
+	FinishedAt     *time.Time
+}
+
+// Job is a crawl running in the background
+type Job struct {
+	id        string
+	options   JobOptions
+	createdAt time.Time
+	cancel    context.CancelFunc
+	done      chan struct{}
+
+	total  atomic.Int64
+	ok     atomic.Int64
+	failed atomic.Int64
+	stage  atomic.Pointer[pipeline.Stage[*dto.CreateUserRequest]] // crawl stage of the current level
+
+	mu         sync.RWMutex
+	status     JobStatus
+	err        error
+	finishedAt time.Time
+	cancelled  bool
+}
+
+// ID returns the job identifier
+func (j *Job) ID() string {
+	return j.id
+}
+
+// Done returns a channel closed when the job has finished
+func (j *Job) Done() <-chan struct{} {
+	return j.done
+}
+
+// Snapshot returns the current progress of the job
+func (j *Job) Snapshot() JobSnapshot {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	snapshot := JobSnapshot{
+		ID:        j.id,
+		Options:   j.options,
+		Status:    j.status,
+		Done:      j.ok.Load(),
+		Failed:    j.failed.Load(),
+		CreatedAt: j.createdAt,
+	}
+	snapshot.Queued = j.total.Load() - snapshot.Done - snapshot.Failed
+
+	if j.err != nil {
+		snapshot.Error = j.err.Error()
+	}
+	if stage := j.stage.Load(); stage != nil {
+		snapshot.Pool = stage.Stats()
+	}
+
+	end := time.Now()
+	if !j.finishedAt.IsZero() {
+		finishedAt := j.finishedAt
+		snapshot.FinishedAt = &finishedAt
+		end = finishedAt
+	}
+
+	if elapsed := end.Sub(j.createdAt).Seconds(); elapsed > 0 {
+		snapshot.PagesPerSecond = float64(snapshot.Done+snapshot.Failed) / elapsed
+	}
+	if snapshot.Status == JobRunning && snapshot.PagesPerSecond > 0 {
+		snapshot.ETA = time.Duration(float64(snapshot.Queued) / snapshot.PagesPerSecond * float64(time.Second))
+	}
+
+	return snapshot
+}
+
+// finishTime returns when the job finished, or the zero time while it runs
+func (j *Job) finishTime() time.Time {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.finishedAt
+}
+
+// finish records the final state of the job
+func (j *Job) finish(err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.finishedAt = time.Now()
+	switch {
+	case j.cancelled:
+		j.status = JobCancelled
+	case err != nil:
+		j.status = JobFailed
+		j.err = err
+	default:
+		j.status = JobCompleted
+	}
+
+	close(j.done)
+}
+
+// Manager is the registry of crawl jobs
+type Manager struct {
+	ctx      context.Context
+	handler  ResultHandler
+	httpPool *commonHttp.HTTPClientPool
+	config   settings.Crawler
+
+	mu   sync.RWMutex
+	jobs map[string]*Job
+}
+
+// NewManager creates a job registry. Jobs are cancelled when ctx is done
+// and crawled pages are passed to handler in batches.
+func NewManager(ctx context.Context, handler ResultHandler) *Manager {
+	config := crawlerConfig()
+
+	m := &Manager{
+		ctx:      ctx,
+		handler:  handler,
+		httpPool: createHTTPPool(config),
+		config:   config,
+		jobs:     make(map[string]*Job),
+	}
+	go m.closeWhenDone()
+
+	return m
+}
+
+// closeWhenDone closes the HTTP pool once ctx is done and the cancelled
+// jobs have finished
+func (m *Manager) closeWhenDone() {
+	<-m.ctx.Done()
+
+	m.mu.RLock()
+	jobs := make([]*Job, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		jobs = append(jobs, job)
+	}
+	m.mu.RUnlock()
+
+	for _, job := range jobs {
+		<-job.done
+	}
+	closeHTTPPool(m.httpPool)
+}
+
+// Start launches a crawl job in the background
+func (m *Manager) Start(opts JobOptions) (*Job, error) {
+	if len(opts.Seeds) == 0 && opts.File == "" {
+		return nil, ErrNoSeeds
+	}
+
+	ctx, cancel := context.WithCancel(m.ctx)
+	job := &Job{
+		id:        primitive.NewObjectID().Hex(),
+		options:   opts,
+		createdAt: time.Now(),
+		cancel:    cancel,
+		done:      make(chan struct{}),
+		status:    JobRunning,
+	}
+
+	m.mu.Lock()
+	if err := m.ctx.Err(); err != nil {
+		// The HTTP pool is closed once the manager is done
+		m.mu.Unlock()
+		cancel()
+		return nil, err
+	}
+	m.prune()
+	if m.running() >= m.config.Jobs.MaxRunning {
+		m.mu.Unlock()
+		cancel()
+		return nil, ErrTooManyJobs
+	}
+	m.jobs[job.id] = job
+	m.mu.Unlock()
+
+	go func() {
+		defer cancel()
+
+		err := m.run(ctx, job)
+		job.finish(err)
+
+		global.Logger.Info("Crawl job finished",
+			zap.String("job", job.id),
+			zap.String("status", string(job.Snapshot().Status)),
+			zap.Error(err),
+		)
+	}()
+
+	return job, nil
+}
+
+// running counts the jobs that have not finished. The caller must hold m.mu.
+func (m *Manager) running() int {
+	var n int
+	for _, job := range m.jobs {
+		if job.finishTime().IsZero() {
+			n++
+		}
+	}
+	return n
+}
+
+// prune drops the finished jobs older than the retention period and the
+// oldest finished jobs beyond the configured maximum. Running jobs are
+// always kept. The caller must hold m.mu.
+func (m *Manager) prune() {
+	cutoff := time.Now().Add(-utils.ToDuration(m.config.Jobs.Retention))
+
+	var finished []*Job
+	for id, job := range m.jobs {
+		finishedAt := job.finishTime()
+		switch {
+		case finishedAt.IsZero():
+		case finishedAt.Before(cutoff):
+			delete(m.jobs, id)
+		default:
+			finished = append(finished, job)
+		}
+	}
+
+	if excess := len(finished) - m.config.Jobs.MaxFinished; excess > 0 {
+		sort.Slice(finished, func(i, k int) bool {
+			return finished[i].finishTime().Before(finished[k].finishTime())
+		})
+		for _, job := range finished[:excess] {
+			delete(m.jobs, job.id)
+		}
+	}
+}
+
+// Get returns a job by ID
+func (m *Manager) Get(id string) (*Job, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	return job, nil
+}
+
+// List returns every job, newest first
+func (m *Manager) List() []*Job {
+	m.mu.RLock()
+	jobs := make([]*Job, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		jobs = append(jobs, job)
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(jobs, func(i, k int) bool {
+		return jobs[i].createdAt.After(jobs[k].createdAt)
+	})
+	return jobs
+}
+
+// Cancel stops a running job. Cancelling a finished job is a no-op.
+func (m *Manager) Cancel(id string) (*Job, error) {
+	job, err := m.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	job.mu.Lock()
+	if job.status == JobRunning {
+		job.cancelled = true
+	}
+	job.mu.Unlock()
+
+	job.cancel()
+	<-job.done
+
+	return job, nil
+}
+
+// Resize changes the concurrency of a running job
+func (m *Manager) Resize(id string, concurrency int) (*Job, error) {
+	job, err := m.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	job.mu.Lock()
+	job.options.Concurrency = concurrency
+	job.mu.Unlock()
+
+	if stage := job.stage.Load(); stage != nil {
+		if err := stage.Resize(concurrency); err != nil && !errors.Is(err, goroutine.ErrPoolClosed) {
+			return nil, err
+		}
+	}
+
+	return job, nil
+}
+
+// run crawls the seeds breadth-first, one level of links at a time
+func (m *Manager) run(ctx context.Context, job *Job) error {
+	seeds, err := m.seeds(job.options)
+	if err != nil {
+		return err
+	}
+
+	visited := make(map[string]bool)
+	var level []string
+	for _, seed := range seeds {
+		if seed = utils.NormalizeTitle(seed); seed != "" && !visited[seed] {
+			visited[seed] = true
+			level = append(level, seed)
+		}
+	}
+
+	var links map[string]int
+	for depth := 0; len(level) > 0 && depth <= job.options.Depth; depth++ {
+		job.total.Add(int64(len(level)))
+
+		next, nextLinks, err := m.crawlLevel(ctx, job, level, links, visited, depth < job.options.Depth)
+		if err != nil {
+			return err
+		}
+		level, links = next, nextLinks
+	}
+
+	return ctx.Err()
+}
+
+// seeds collects the seed titles of a job
+func (m *Manager) seeds(opts JobOptions) ([]string, error) {
+	seeds := append([]string{}, opts.Seeds...)
+	if opts.File == "" {
+		return seeds, nil
+	}
+
+	err := readPagesFromFile(opts.File, func(page string) error {
+		seeds = append(seeds, page)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read seed file: %w", err)
+	}
+
+	return seeds, nil
+}
+
+// crawlLevel crawls one level of pages and returns the unvisited neighbors
+// to crawl next when expand is set, with the number of crawled pages linking
+// to each of them. Pages of the level with more links are crawled first, so
+// a job that is cancelled early has covered the best connected pages.
+func (m *Manager) crawlLevel(ctx context.Context, job *Job, level []string, links map[string]int, visited map[string]bool, expand bool) ([]string, map[string]int, error) {
+	opts := []pipeline.Option{
+		pipeline.WithName("crawl-" + job.id),
+		pipeline.WithErrorHandler(func(err error) error {
+			job.failed.Add(1)
+			logTaskError("Crawl error", err, zap.String("job", job.id))
+			return nil
+		}),
+	}
+	var priority func(page string) int
+	if len(links) > 0 {
+		priority = func(page string) int {
+			return links[page]
+		}
+	}
+
+	job.mu.RLock()
+	if job.options.Concurrency > 0 {
+		opts = append(opts, pipeline.WithConcurrency(job.options.Concurrency))
+	}
+	job.mu.RUnlock()
+
+	// level -> crawl -> store
+	p := pipeline.New(ctx)
+	pages := pipeline.FromSlice(p, level)
+	records := crawlPages(p, pages, m.httpPool, m.config, priority, opts...)
+
+	job.stage.Store(records)
+	defer job.stage.Store(nil)
+
+	store := m.storer(ctx, job)
+	defer store.Close()
+
+	var next []string
+	nextLinks := make(map[string]int)
+	pipeline.Sink(p, records, func(ctx context.Context, result *dto.CreateUserRequest) error {
+		if err := store.Add(result); err != nil {
+			return err
+		}
+
+		visited[result.Name] = true
+		for _, alias := range result.Aliases {
+			visited[alias] = true
+		}
+		if !expand {
+			return nil
+		}
+		for _, neighbor := range result.Neighbors {
+			if !visited[neighbor] {
+				visited[neighbor] = true
+				next = append(next, neighbor)
+				nextLinks[neighbor] = 1
+				continue
+			}
+			if _, ok := nextLinks[neighbor]; ok {
+				nextLinks[neighbor]++
+			}
+		}
+		return nil
+	})
+
+	return next, nextLinks, p.Wait()
+}
+
+// storer batches the crawled pages of a job into the result handler and
+// counts the stored and failed pages
+func (m *Manager) storer(ctx context.Context, job *Job) *goroutine.Batcher[*dto.CreateUserRequest] {
+	flush := func(ctx context.Context, records []*dto.CreateUserRequest) []error {
+		if m.handler == nil {
+			job.ok.Add(int64(len(records)))
+			return nil
+		}
+
+		errs := m.handler(ctx, records)
+
+		stored := len(records)
+		for _, err := range errs {
+			if err != nil {
+				stored--
+			}
+		}
+		job.ok.Add(int64(stored))
+
+		return errs
+	}
+
+	onError := func(record *dto.CreateUserRequest, err error) {
+		job.failed.Add(1)
+		global.Logger.Error("Failed to store crawled page",
+			zap.String("job", job.id),
+			zap.String("name", record.Name),
+			zap.Error(err),
+		)
+	}
+
+	return goroutine.NewBatcher(ctx, flush, onError,
+		goroutine.WithBatchSize(storeBatchSize),
+		goroutine.WithMaxLatency(storeMaxLatency),
+	)
+}
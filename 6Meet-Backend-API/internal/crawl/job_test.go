@@ -0,0 +1,62 @@
+package crawl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/settings"
+)
+
+func TestManagerPrune(t *testing.T) {
+	now := time.Now()
+	m := &Manager{
+		config: settings.Crawler{Jobs: settings.Jobs{Retention: 3600, MaxFinished: 2}},
+		jobs:   make(map[string]*Job),
+	}
+
+	add := func(id string, finishedAgo time.Duration) {
+		job := &Job{id: id, status: JobRunning}
+		if finishedAgo > 0 {
+			job.status = JobCompleted
+			job.finishedAt = now.Add(-finishedAgo)
+		}
+		m.jobs[id] = job
+	}
+
+	add("running", 0)
+	add("expired", 2*time.Hour)
+	for i := 1; i <= 3; i++ {
+		add(fmt.Sprintf("finished-%d", i), time.Duration(i)*time.Minute)
+	}
+
+	m.prune()
+
+	for _, id := range []string{"running", "finished-1", "finished-2"} {
+		if _, ok := m.jobs[id]; !ok {
+			t.Errorf("job %s was pruned", id)
+		}
+	}
+	for _, id := range []string{"expired", "finished-3"} {
+		if _, ok := m.jobs[id]; ok {
+			t.Errorf("job %s was kept", id)
+		}
+	}
+}
+
+func TestManagerStartLimitsRunningJobs(t *testing.T) {
+	m := &Manager{
+		ctx:    context.Background(),
+		config: settings.Crawler{Jobs: settings.Jobs{Retention: 3600, MaxFinished: 2, MaxRunning: 1}},
+		jobs:   map[string]*Job{"running": {id: "running", status: JobRunning}},
+	}
+
+	if _, err := m.Start(JobOptions{Seeds: []string{"Go"}}); !errors.Is(err, ErrTooManyJobs) {
+		t.Errorf("Start() error = %v; want ErrTooManyJobs", err)
+	}
+	if len(m.jobs) != 1 {
+		t.Errorf("registry holds %d jobs; want the running one only", len(m.jobs))
+	}
+}
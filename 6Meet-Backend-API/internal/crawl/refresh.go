@@ -0,0 +1,258 @@
+package crawl
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/global"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/core/cache"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/core/dto"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/core/entity"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/ports"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/common/goroutine"
+	commonHttp "github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/common/http"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/common/pipeline"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/settings"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/utils"
+	"go.uber.org/zap"
+)
+
+// refreshResult is the outcome of re-crawling a single user
+type refreshResult struct {
+	user    *entity.User
+	record  *dto.CreateUserRequest // nil when the page is missing
+	added   int
+	removed int
+}
+
+// missing reports whether the page of the user no longer exists
+func (r *refreshResult) missing() bool {
+	return r.record == nil
+}
+
+// changed reports whether the re-crawl found anything to write: new links,
+// a new title or a redirect that is not stored as an alias yet
+func (r *refreshResult) changed() bool {
+	if r.missing() {
+		return false
+	}
+	if r.added > 0 || r.removed > 0 || r.record.Name != r.user.Name {
+		return true
+	}
+	return slices.ContainsFunc(r.record.Aliases, func(alias string) bool {
+		return alias != r.user.Name && !slices.Contains(r.user.Aliases, alias)
+	})
+}
+
+// RefreshTask re-crawls a stored user
+type RefreshTask struct {
+	user  *entity.User
+	crawl *CrawlTask
+}
+
+// TaskID identifies the task by the stored page title
+func (t *RefreshTask) TaskID() string {
+	return t.user.Name
+}
+
+// Process implements the Task interface for WorkerPool. A page that no
+// longer exists is not an error, so the user is not re-crawled every run.
+func (t *RefreshTask) Process(ctx context.Context) (*refreshResult, error) {
+	record, err := t.crawl.Process(ctx)
+	if errors.Is(err, ErrPageMissing) {
+		return &refreshResult{user: t.user}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	added, removed := diffNeighbors(t.user.Neighbors, record.Neighbors)
+
+	return &refreshResult{
+		user:    t.user,
+		record:  record,
+		added:   added,
+		removed: removed,
+	}, nil
+}
+
+// diffNeighbors counts the links added to and removed from a neighbor list
+func diffNeighbors(before, after []string) (added, removed int) {
+	old := make(map[string]bool, len(before))
+	for _, name := range before {
+		old[name] = true
+	}
+
+	for _, name := range after {
+		if old[name] {
+			delete(old, name)
+			continue
+		}
+		added++
+	}
+
+	return added, len(old)
+}
+
+// Refresher periodically re-crawls users whose neighbor lists are stale
+type Refresher struct {
+	userRepo ports.UserRepository
+	httpPool *commonHttp.HTTPClientPool
+	config   settings.Crawler
+	cursor   *entity.User // last user visited, nil to start from the oldest
+}
+
+// NewRefresher creates a new Refresher
+func NewRefresher(userRepo ports.UserRepository) *Refresher {
+	config := crawlerConfig()
+
+	return &Refresher{
+		userRepo: userRepo,
+		httpPool: createHTTPPool(config),
+		config:   config,
+	}
+}
+
+// Run refreshes stale users every configured interval until ctx is done
+func (r *Refresher) Run(ctx context.Context) {
+	ticker := time.NewTicker(utils.ToDuration(r.config.Refresh.Interval))
+	defer ticker.Stop()
+	defer closeHTTPPool(r.httpPool)
+
+	for {
+		if err := r.RefreshOnce(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			global.Logger.Error("Failed to refresh stale pages", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RefreshOnce re-crawls one batch of stale users, oldest first, writes the
+// users whose links or titles changed and only touches the UpdatedAt of the
+// others so they are not stale any more. Users whose page is missing are
+// touched as well and retried once they are stale again. Users whose
+// re-crawl failed stay stale, so
+// the refresher remembers where it stopped and continues from there on the
+// next run, wrapping around once every stale user has been visited.
+func (r *Refresher) RefreshOnce(ctx context.Context) error {
+	before := time.Now().Add(-utils.ToDuration(r.config.Refresh.StaleAfter))
+
+	users, err := r.userRepo.FindStale(ctx, before, r.cursor, r.config.Refresh.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	if len(users) < r.config.Refresh.BatchSize {
+		r.cursor = nil
+	} else {
+		r.cursor = users[len(users)-1]
+	}
+
+	if len(users) == 0 {
+		return nil
+	}
+
+	start := time.Now()
+	global.Logger.Info("Refreshing stale pages", zap.Int("pages", len(users)))
+
+	tasks := make([]*RefreshTask, 0, len(users))
+	for _, user := range users {
+		tasks = append(tasks, &RefreshTask{
+			user: user,
+			crawl: &CrawlTask{
+				name:     user.Name,
+				httpPool: r.httpPool,
+				config:   r.config,
+			},
+		})
+	}
+
+	// stale users -> re-crawl -> save
+	p := pipeline.New(ctx)
+	pending := pipeline.FromSlice(p, tasks)
+
+	refresh := func(ctx context.Context, task *RefreshTask) (*refreshResult, error) {
+		return task.Process(ctx)
+	}
+	results := pipeline.Map(p, pending, refresh,
+		pipeline.WithName("refresher"),
+		pipeline.WithConcurrency(pageConcurrency),
+		pipeline.WithPoolOptions(
+			goroutine.WithTimeout(pageTimeout),
+			goroutine.WithRetryPolicy(pageRetryPolicy),
+		),
+		pipeline.WithErrorHandler(func(err error) error {
+			logTaskError("Refresh error", err)
+			return nil
+		}),
+	)
+
+	var updated, missing int
+	var unchanged []string
+	pipeline.Sink(p, results, func(ctx context.Context, result *refreshResult) error {
+		if result.missing() {
+			missing++
+			global.Logger.Warn("Refreshed page is missing", zap.String("name", result.user.Name))
+		}
+		if !result.changed() {
+			unchanged = append(unchanged, result.user.ID)
+			return nil
+		}
+		if r.save(ctx, result) {
+			updated++
+		}
+		return nil
+	})
+
+	err = p.Wait()
+
+	if len(unchanged) > 0 {
+		if touchErr := r.userRepo.Touch(ctx, unchanged); touchErr != nil {
+			global.Logger.Error("Failed to mark unchanged pages as refreshed",
+				zap.Int("pages", len(unchanged)),
+				zap.Error(touchErr),
+			)
+		}
+	}
+
+	global.Logger.Info("Refreshed stale pages",
+		zap.Int("pages", len(users)),
+		zap.Int("updated", updated),
+		zap.Int("unchanged", len(unchanged)-missing),
+		zap.Int("missing", missing),
+		zap.Duration("elapsed", time.Since(start).Round(time.Second)),
+	)
+
+	return err
+}
+
+// save writes the re-crawled links of a user and logs the diff
+func (r *Refresher) save(ctx context.Context, result *refreshResult) bool {
+	user := result.user
+
+	user.MergeTitles(result.record.Name, result.record.Aliases)
+	user.Neighbors = result.record.Neighbors
+
+	if err := r.userRepo.Update(ctx, user.ID, user); err != nil {
+		global.Logger.Error("Failed to save refreshed page",
+			zap.String("name", user.Name),
+			zap.Error(err),
+		)
+		return false
+	}
+	cache.InvalidateUsers(ctx, user.ID)
+
+	global.Logger.Info("Refreshed page",
+		zap.String("name", user.Name),
+		zap.Int("added", result.added),
+		zap.Int("removed", result.removed),
+	)
+	return true
+}
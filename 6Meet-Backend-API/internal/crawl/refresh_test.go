@@ -0,0 +1,35 @@
+package crawl
+
+import (
+	"testing"
+
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/core/dto"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/core/entity"
+)
+
+func TestRefreshResultChanged(t *testing.T) {
+	user := &entity.User{Name: "Go", Aliases: []string{"Golang"}, Neighbors: []string{"C"}}
+
+	tests := []struct {
+		name   string
+		record *dto.CreateUserRequest
+		added  int
+		want   bool
+	}{
+		{name: "same", record: &dto.CreateUserRequest{Name: "Go", Aliases: []string{"Golang"}}, want: false},
+		{name: "known alias only", record: &dto.CreateUserRequest{Name: "Go"}, want: false},
+		{name: "new link", record: &dto.CreateUserRequest{Name: "Go"}, added: 1, want: true},
+		{name: "renamed", record: &dto.CreateUserRequest{Name: "Go (language)"}, want: true},
+		{name: "new redirect", record: &dto.CreateUserRequest{Name: "Go", Aliases: []string{"Golang", "Go lang"}}, want: true},
+		{name: "missing page", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &refreshResult{user: user, record: tt.record, added: tt.added}
+			if got := result.changed(); got != tt.want {
+				t.Errorf("changed() = %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,59 +4,188 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"os"
-	"sync"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/huynhanx03/6Meet/6Meet-Backend-API/global"
 	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/core/dto"
 	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/common/goroutine"
 	commonHttp "github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/common/http"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/common/pipeline"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/settings"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/utils"
 	"go.uber.org/zap"
 )
 
+const (
+	// apiURL is the Wikipedia MediaWiki API endpoint
+	apiURL = "https://en.wikipedia.org/w/api.php"
+)
+
+// ErrPageMissing is returned when a title does not resolve to an existing page
+var ErrPageMissing = errors.New("page does not exist")
+
+// APIError is an error the MediaWiki API reports in the body of a
+// successful response, e.g. readonly or internal_api_error. It says
+// nothing about whether the page exists, so the page is retried.
+type APIError struct {
+	Code string `json:"code"`
+	Info string `json:"info"`
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	return fmt.Sprintf("mediawiki api error %s: %s", e.Code, e.Info)
+}
+
+// TitleMapping represents a "from" -> "to" entry of the normalized/redirects lists
+type TitleMapping struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
 // ApiResponse represents the structure of the Wikipedia API response
 type ApiResponse struct {
 	Query struct {
-		Pages map[string]struct {
-			Links []struct {
-				Title string `json:"title"`
-				Ns    int    `json:"ns"`
-			} `json:"links"`
+		Normalized []TitleMapping `json:"normalized"`
+		Redirects  []TitleMapping `json:"redirects"`
+		Pages      map[string]struct {
+			Title   string  `json:"title"`
+			Ns      int     `json:"ns"`
+			Missing *string `json:"missing"`
+			Invalid *string `json:"invalid"`
 		} `json:"pages"`
 	} `json:"query"`
-	Continue struct {
-		Plcontinue string `json:"plcontinue"`
-	} `json:"continue"`
+	Continue map[string]string `json:"continue"`
+	Error    *APIError         `json:"error"`
 }
 
 // CrawlTask represents a single crawl operation
 type CrawlTask struct {
 	name     string
 	httpPool *commonHttp.HTTPClientPool
+	config   settings.Crawler
+}
+
+// TaskID identifies the task by its page title
+func (t *CrawlTask) TaskID() string {
+	return t.name
 }
 
 // Process implements the Task interface for WorkerPool
 func (t *CrawlTask) Process(ctx context.Context) (*dto.CreateUserRequest, error) {
-	links, err := t.fetchLinks(ctx, t.name)
+	title, aliases, err := t.resolveTitle(ctx, t.name)
+	if err != nil {
+		return nil, err
+	}
+
+	links, err := t.fetchLinks(ctx, title)
 	if err != nil {
 		return nil, err
 	}
 
 	return &dto.CreateUserRequest{
-		Name:      t.name,
+		Name:      title,
+		Aliases:   aliases,
 		Neighbors: links,
 	}, nil
 }
 
-// fetchLinks fetches all links from a Wikipedia page
+// query performs a MediaWiki API query and decodes the response. Errors
+// reported in the response body are returned as an *APIError.
+func (t *CrawlTask) query(ctx context.Context, params url.Values) (*ApiResponse, error) {
+	params.Set("action", "query")
+	params.Set("format", "json")
+	if t.config.MaxLag > 0 {
+		params.Set("maxlag", strconv.Itoa(t.config.MaxLag))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", t.config.UserAgent)
+
+	resp, err := t.httpPool.RequestWithRetry(ctx, req, t.config.MaxRetries)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result ApiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode JSON: %w", err)
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &result, nil
+}
+
+// resolveTitle follows title normalization and redirects of a page and
+// returns its canonical title together with every alias seen on the way
+func (t *CrawlTask) resolveTitle(ctx context.Context, pageTitle string) (string, []string, error) {
+	params := url.Values{}
+	params.Set("titles", pageTitle)
+	params.Set("redirects", "1")
+
+	result, err := t.query(ctx, params)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var title string
+	for _, page := range result.Query.Pages {
+		if page.Missing != nil || page.Invalid != nil {
+			return "", nil, fmt.Errorf("%w: %s", ErrPageMissing, pageTitle)
+		}
+		title = page.Title
+	}
+	if title == "" {
+		return "", nil, fmt.Errorf("%w: %s", ErrPageMissing, pageTitle)
+	}
+
+	return title, collectAliases(title, pageTitle, result.Query.Normalized, result.Query.Redirects), nil
+}
+
+// collectAliases gathers the distinct titles that lead to the canonical title
+func collectAliases(title, requested string, mappings ...[]TitleMapping) []string {
+	seen := map[string]bool{title: true}
+	var aliases []string
+
+	add := func(alias string) {
+		if alias == "" || seen[alias] {
+			return
+		}
+		seen[alias] = true
+		aliases = append(aliases, alias)
+	}
+
+	add(requested)
+	for _, list := range mappings {
+		for _, m := range list {
+			add(m.From)
+			add(m.To)
+		}
+	}
+
+	return aliases
+}
+
+// fetchLinks fetches all links from a Wikipedia page. Link targets are
+// resolved through redirects and missing pages are dropped, so every
+// neighbor is the canonical title of an existing article.
 func (t *CrawlTask) fetchLinks(ctx context.Context, pageTitle string) ([]string, error) {
-	baseURL := "https://en.wikipedia.org/w/api.php?action=query&prop=links&format=json&plnamespace=0&pllimit=max&titles=%s"
 	var allLinks []string
-	plcontinue := ""
+	seen := make(map[string]bool)
+	cont := map[string]string{}
 
 	for {
 		select {
@@ -65,65 +194,116 @@ func (t *CrawlTask) fetchLinks(ctx context.Context, pageTitle string) ([]string,
 		default:
 		}
 
-		encodedTitle := url.QueryEscape(pageTitle)
-		requestURL := fmt.Sprintf(baseURL, encodedTitle)
-		if plcontinue != "" {
-			requestURL += "&plcontinue=" + url.QueryEscape(plcontinue)
-		}
-
-		req, err := http.NewRequestWithContext(ctx, "GET", requestURL, nil)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create request: %w", err)
+		params := url.Values{}
+		params.Set("titles", pageTitle)
+		params.Set("generator", "links")
+		params.Set("gplnamespace", "0")
+		params.Set("gpllimit", "max")
+		params.Set("redirects", "1")
+		for k, v := range cont {
+			params.Set(k, v)
 		}
-		req.Header.Set("User-Agent", "6MeetBot/1.0")
 
-		resp, err := t.httpPool.RequestWithRetry(ctx, req, 3)
+		result, err := t.query(ctx, params)
 		if err != nil {
-			return nil, fmt.Errorf("request failed: %w", err)
-		}
-		defer resp.Body.Close()
-
-		var result ApiResponse
-		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-			return nil, fmt.Errorf("failed to decode JSON: %w", err)
+			return nil, err
 		}
 
 		for _, page := range result.Query.Pages {
-			for _, link := range page.Links {
-				if link.Ns == 0 {
-					allLinks = append(allLinks, link.Title)
-				}
+			if page.Ns != 0 || page.Missing != nil || page.Invalid != nil || seen[page.Title] {
+				continue
 			}
+			seen[page.Title] = true
+			allLinks = append(allLinks, page.Title)
 		}
 
-		plcontinue = result.Continue.Plcontinue
-		if plcontinue == "" {
+		if len(result.Continue) == 0 {
 			return allLinks, nil // Return here when no more pages
 		}
-
-		// time.Sleep(100 * time.Millisecond)
+		cont = result.Continue
 	}
 }
 
 // createHTTPPool creates and configures the HTTP client pool
-func createHTTPPool() *commonHttp.HTTPClientPool {
+func createHTTPPool(crawler settings.Crawler) *commonHttp.HTTPClientPool {
 	config := &commonHttp.HTTPClientConfig{
-		Timeout:         30 * time.Minute,
-		MaxIdleConns:    100,
-		IdleConnTimeout: 90 * time.Second,
-		MaxConnsPerHost: 64,
+		Timeout:           30 * time.Minute,
+		MaxIdleConns:      100,
+		IdleConnTimeout:   90 * time.Second,
+		MaxConnsPerHost:   64,
+		RateLimit:         crawler.RateLimit,
+		RateBurst:         crawler.RateBurst,
+		MaxRetryAfter:     utils.ToDuration(crawler.MaxRetryAfter),
+		EnableCache:       crawler.Cache.Enabled,
+		CacheExpiration:   utils.ToDuration(crawler.Cache.TTL),
+		CacheMaxBytes:     crawler.Cache.MaxBytes,
+		CacheDir:          crawler.Cache.Dir,
+		CacheDiskMaxBytes: crawler.Cache.DiskMaxBytes,
+		Breaker: commonHttp.BreakerConfig{
+			FailureRatio:   crawler.Breaker.FailureRatio,
+			MinRequests:    crawler.Breaker.MinRequests,
+			Window:         utils.ToDuration(crawler.Breaker.Window),
+			Cooldown:       utils.ToDuration(crawler.Breaker.Cooldown),
+			HalfOpenProbes: crawler.Breaker.HalfOpenProbes,
+			OnStateChange:  logBreakerChange,
+		},
+		CassetteMode: commonHttp.CassetteMode(crawler.Cassette.Mode),
+		CassettePath: crawler.Cassette.Path,
 	}
 	return commonHttp.NewHTTPClientPool(config)
 }
 
-// createWorkerPool creates and starts a worker pool
-func createWorkerPool(ctx context.Context) *goroutine.WorkerPool[*dto.CreateUserRequest] {
-	pool := goroutine.NewIOExecutor[*dto.CreateUserRequest](ctx)
-	return pool
+// closeHTTPPool closes a crawler HTTP pool once it is no longer used, so a
+// recorded cassette is complete
+func closeHTTPPool(httpPool *commonHttp.HTTPClientPool) {
+	if err := httpPool.Close(); err != nil {
+		global.Logger.Error("Failed to close HTTP client pool", zap.Error(err))
+	}
+}
+
+// crawlPages starts a pipeline stage that crawls every page title of in,
+// highest priority first when priority is not nil
+func crawlPages(p *pipeline.Pipeline, in *pipeline.Stage[string], httpPool *commonHttp.HTTPClientPool, config settings.Crawler, priority func(page string) int, opts ...pipeline.Option) *pipeline.Stage[*dto.CreateUserRequest] {
+	fetch := func(ctx context.Context, page string) (*dto.CreateUserRequest, error) {
+		task := &CrawlTask{
+			name:     page,
+			httpPool: httpPool,
+			config:   config,
+		}
+		return task.Process(ctx)
+	}
+
+	opts = append([]pipeline.Option{
+		pipeline.WithConcurrency(pageConcurrency),
+		pipeline.WithPoolOptions(
+			goroutine.WithTimeout(pageTimeout),
+			goroutine.WithRetryPolicy(pageRetryPolicy),
+		),
+	}, opts...)
+
+	if priority != nil {
+		return pipeline.MapByPriority(p, in, fetch, priority, opts...)
+	}
+	return pipeline.Map(p, in, fetch, opts...)
+}
+
+// logBreakerChange logs circuit breaker transitions of the crawled hosts
+func logBreakerChange(host string, from, to commonHttp.BreakerState) {
+	fields := []zap.Field{
+		zap.String("host", host),
+		zap.String("from", from.String()),
+		zap.String("to", to.String()),
+	}
+
+	if to == commonHttp.BreakerOpen {
+		global.Logger.Warn("Circuit breaker opened, pausing requests", fields...)
+		return
+	}
+	global.Logger.Info("Circuit breaker state changed", fields...)
 }
 
-// readPagesFromFile reads pages from a file and sends them to a channel
-func readPagesFromFile(ctx context.Context, filename string, pagesChan chan<- string) error {
+// readPagesFromFile reads the non-empty lines of a file and passes them to emit
+func readPagesFromFile(filename string, emit func(page string) error) error {
 	// Open the file
 	file, err := os.Open(filename)
 	if err != nil {
@@ -138,10 +318,12 @@ func readPagesFromFile(ctx context.Context, filename string, pagesChan chan<- st
 	// Read the file line by line
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case pagesChan <- scanner.Text():
+		page := strings.TrimSpace(scanner.Text())
+		if page == "" {
+			continue
+		}
+		if err := emit(page); err != nil {
+			return err
 		}
 	}
 
@@ -157,55 +339,6 @@ func readPagesFromFile(ctx context.Context, filename string, pagesChan chan<- st
 	return nil
 }
 
-// processResults handles the results from the worker pool
-func processResults(resultsC <-chan *dto.CreateUserRequest, errorsC <-chan error, wg *sync.WaitGroup) {
-	wg.Add(2)
-
-	// Process successful results
-	go func() {
-		defer wg.Done()
-		for result := range resultsC {
-			global.Logger.Info("Processed page",
-				zap.String("name", result.Name),
-				zap.Int("neighbors", len(result.Neighbors)),
-			)
-		}
-	}()
-
-	// Process errors
-	go func() {
-		defer wg.Done()
-		for err := range errorsC {
-			global.Logger.Error("Crawl error", zap.Error(err))
-		}
-	}()
-}
-
-// submitTasks submits pages to the worker pool for processing
-func submitTasks(pagesChan <-chan string, workerPool *goroutine.WorkerPool[*dto.CreateUserRequest], httpPool *commonHttp.HTTPClientPool) error {
-	for page := range pagesChan {
-		if page == "" {
-			continue
-		}
-
-		task := &CrawlTask{
-			name:     page,
-			httpPool: httpPool,
-		}
-
-		// Submit blocks until a worker receives the task or context is done
-		if err := workerPool.Submit(task); err != nil {
-			global.Logger.Error("Failed to submit task",
-				zap.String("page", page),
-				zap.Error(err),
-			)
-			return err
-		}
-	}
-
-	return nil
-}
-
 // CrawlData processes pages from a file concurrently
 func CrawlData(filename string) error {
 	start := time.Now()
@@ -215,44 +348,36 @@ func CrawlData(filename string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
 	defer cancel()
 
-	// Initialize components
-	httpPool := createHTTPPool()
-	workerPool := createWorkerPool(ctx)
-	workerPool.Start()
-
-	// Start reading pages from file
-	pagesChan := make(chan string, 1000)
-
-	var wg sync.WaitGroup
-	defer wg.Wait()
-
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		defer close(pagesChan)
-		if err := readPagesFromFile(ctx, filename, pagesChan); err != nil {
-			global.Logger.Error("Failed to read pages",
-				zap.String("filename", filename),
-				zap.Error(err),
-			)
-			cancel()
-		}
-	}()
-
-	// Process results
-	resultsC, errorsC := workerPool.Results()
-	processResults(resultsC, errorsC, &wg)
-
-	// Wait for worker pool to finish
-	defer func() {
-		workerPool.Shutdown()
-		global.Logger.Info(fmt.Sprintf("Crawling completed in %s", time.Since(start).Round(time.Second)))
-	}()
+	config := crawlerConfig()
+	httpPool := createHTTPPool(config)
+	defer closeHTTPPool(httpPool)
+
+	// file -> crawl -> log
+	p := pipeline.New(ctx)
+
+	pages := pipeline.From(p, func(ctx context.Context, emit func(string) error) error {
+		return readPagesFromFile(filename, emit)
+	}, pipeline.WithBuffer(1000))
+
+	records := crawlPages(p, pages, httpPool, config, nil,
+		pipeline.WithName("seeder"),
+		pipeline.WithErrorHandler(func(err error) error {
+			logTaskError("Crawl error", err)
+			return nil
+		}),
+	)
+
+	pipeline.Sink(p, records, func(ctx context.Context, result *dto.CreateUserRequest) error {
+		global.Logger.Info("Processed page",
+			zap.String("name", result.Name),
+			zap.Int("aliases", len(result.Aliases)),
+			zap.Int("neighbors", len(result.Neighbors)),
+		)
+		return nil
+	})
 
-	// Submit tasks to the worker pool
-	if err := submitTasks(pagesChan, workerPool, httpPool); err != nil {
-		return fmt.Errorf("error submitting tasks: %w", err)
-	}
+	err := p.Wait()
+	global.Logger.Info(fmt.Sprintf("Crawling completed in %s", time.Since(start).Round(time.Second)))
 
-	return nil
+	return err
 }
@@ -0,0 +1,86 @@
+package crawl
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	commonHttp "github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/common/http"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/settings"
+)
+
+// staticTransport answers every request with the same JSON body
+type staticTransport string
+
+func (t staticTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(string(t))),
+		Request:    req,
+	}, nil
+}
+
+func TestResolveTitle(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		title   string
+		aliases []string
+		check   func(error) bool
+	}{
+		{
+			name:    "redirect",
+			body:    `{"query":{"redirects":[{"from":"Golang","to":"Go"}],"pages":{"1":{"title":"Go","ns":0}}}}`,
+			title:   "Go",
+			aliases: []string{"Golang"},
+		},
+		{
+			name:  "missing page",
+			body:  `{"query":{"pages":{"-1":{"title":"Nope","ns":0,"missing":""}}}}`,
+			check: func(err error) bool { return errors.Is(err, ErrPageMissing) },
+		},
+		{
+			name: "api error",
+			body: `{"error":{"code":"readonly","info":"The wiki is currently in read-only mode."}}`,
+			check: func(err error) bool {
+				var apiErr *APIError
+				return errors.As(err, &apiErr) && apiErr.Code == "readonly" && !errors.Is(err, ErrPageMissing)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &CrawlTask{
+				name: "Golang",
+				httpPool: commonHttp.NewHTTPClientPool(&commonHttp.HTTPClientConfig{
+					Transport: staticTransport(tt.body),
+				}),
+				config: settings.Crawler{MaxRetries: 1},
+			}
+
+			title, aliases, err := task.resolveTitle(context.Background(), "Golang")
+			if tt.check != nil {
+				if !tt.check(err) {
+					t.Errorf("resolveTitle() error = %v", err)
+				}
+				// Only missing pages are final
+				if got, want := pageRetryPolicy.Retryable(err), !errors.Is(err, ErrPageMissing); got != want {
+					t.Errorf("Retryable(%v) = %v; want %v", err, got, want)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("resolveTitle() error = %v", err)
+			}
+			if title != tt.title || strings.Join(aliases, ",") != strings.Join(tt.aliases, ",") {
+				t.Errorf("resolveTitle() = %q, %v; want %q, %v", title, aliases, tt.title, tt.aliases)
+			}
+		})
+	}
+}
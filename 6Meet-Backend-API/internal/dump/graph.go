@@ -0,0 +1,87 @@
+package dump
+
+import (
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/core/dto"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/utils"
+)
+
+// maxRedirectHops bounds how many redirects are followed for a single title
+const maxRedirectHops = 5
+
+// graph holds the article index used to turn raw links into records
+type graph struct {
+	articles  map[string]struct{} // canonical titles of existing articles
+	redirects map[string]string   // redirect title -> target title
+	aliases   map[string][]string // canonical title -> redirect titles
+}
+
+func newGraph() *graph {
+	return &graph{
+		articles:  make(map[string]struct{}),
+		redirects: make(map[string]string),
+	}
+}
+
+// addArticle registers an existing, non-redirect article
+func (g *graph) addArticle(title string) {
+	g.articles[title] = struct{}{}
+}
+
+// addRedirect registers a redirect page
+func (g *graph) addRedirect(from, to string) {
+	if from != "" && to != "" && from != to {
+		g.redirects[from] = to
+	}
+}
+
+// buildAliases inverts the redirect table once every redirect is known
+func (g *graph) buildAliases() {
+	g.aliases = make(map[string][]string)
+	for from := range g.redirects {
+		if to, ok := g.resolve(from); ok {
+			g.aliases[to] = append(g.aliases[to], from)
+		}
+	}
+}
+
+// resolve follows redirects and reports whether the title is an existing article
+func (g *graph) resolve(title string) (string, bool) {
+	title = utils.NormalizeTitle(title)
+
+	for i := 0; i < maxRedirectHops; i++ {
+		target, ok := g.redirects[title]
+		if !ok {
+			break
+		}
+		title = target
+	}
+
+	_, ok := g.articles[title]
+	return title, ok
+}
+
+// record builds the user record of an article from its raw link targets.
+// It returns nil when none of the links point at an existing article.
+func (g *graph) record(name string, links []string) *dto.CreateUserRequest {
+	seen := map[string]bool{name: true}
+	var neighbors []string
+
+	for _, link := range links {
+		target, ok := g.resolve(link)
+		if !ok || seen[target] {
+			continue
+		}
+		seen[target] = true
+		neighbors = append(neighbors, target)
+	}
+
+	if len(neighbors) == 0 {
+		return nil
+	}
+
+	return &dto.CreateUserRequest{
+		Name:      name,
+		Aliases:   g.aliases[name],
+		Neighbors: neighbors,
+	}
+}
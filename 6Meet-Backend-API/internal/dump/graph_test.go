@@ -0,0 +1,45 @@
+package dump
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestGraphRecord(t *testing.T) {
+	g := newGraph()
+	g.addArticle("Go")
+	g.addArticle("Rust")
+	g.addArticle("C")
+	g.addRedirect("Golang", "Go")
+	g.addRedirect("Rust lang", "Rust language")
+	g.addRedirect("Rust language", "Rust")
+	g.addRedirect("Loop", "Loop")
+	g.buildAliases()
+
+	rec := g.record("C", []string{"golang", "Rust_lang", "Go", "C", "Missing", "Loop"})
+	if rec == nil {
+		t.Fatal("record() = nil; want a record")
+	}
+	if want := []string{"Go", "Rust"}; !slices.Equal(rec.Neighbors, want) {
+		t.Errorf("Neighbors = %q; want %q", rec.Neighbors, want)
+	}
+
+	aliases := slices.Sorted(slices.Values(g.aliases["Rust"]))
+	if want := []string{"Rust lang", "Rust language"}; !slices.Equal(aliases, want) {
+		t.Errorf("aliases of Rust = %q; want %q", aliases, want)
+	}
+
+	if rec := g.record("Go", []string{"Missing", "Go"}); rec != nil {
+		t.Errorf("record() = %+v; want nil without existing neighbors", rec)
+	}
+}
+
+func TestGraphResolveRedirectLoop(t *testing.T) {
+	g := newGraph()
+	g.addRedirect("A", "B")
+	g.addRedirect("B", "A")
+
+	if _, ok := g.resolve("A"); ok {
+		t.Error("resolve() of a redirect loop reports an article")
+	}
+}
@@ -0,0 +1,313 @@
+package dump
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/global"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/utils"
+	"go.uber.org/zap"
+)
+
+// progressEvery is the number of records between progress log lines
+const progressEvery = 100000
+
+// Options selects the dump files to import. Either XML or Page and
+// PageLinks must be set; Redirect and LinkTarget are optional SQL dumps.
+type Options struct {
+	XML        string // pages-articles XML dump
+	Page       string // page table SQL dump
+	PageLinks  string // pagelinks table SQL dump
+	Redirect   string // redirect table SQL dump (optional)
+	LinkTarget string // linktarget table SQL dump (required by newer pagelinks schemas)
+}
+
+// Validate checks that a complete set of dump files was given
+func (o *Options) Validate() error {
+	switch {
+	case o.XML != "" && (o.Page != "" || o.PageLinks != ""):
+		return errors.New("use either an XML dump or SQL dumps, not both")
+	case o.XML != "":
+		return nil
+	case o.Page == "" || o.PageLinks == "":
+		return errors.New("both page and pagelinks SQL dumps are required")
+	}
+	return nil
+}
+
+// Import reads the dumps and writes one record per article to the sink
+func Import(ctx context.Context, opts Options, sink Sink) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	global.Logger.Info("Import started")
+
+	var (
+		count int
+		err   error
+	)
+	if opts.XML != "" {
+		count, err = importXML(ctx, opts.XML, sink)
+	} else {
+		count, err = importSQL(ctx, opts, sink)
+	}
+	if err != nil {
+		return err
+	}
+
+	global.Logger.Info("Import completed",
+		zap.Int("records", count),
+		zap.Duration("elapsed", time.Since(start).Round(time.Second)),
+	)
+	return nil
+}
+
+// emitter writes records to the sink and reports progress
+type emitter struct {
+	sink  Sink
+	count int
+}
+
+func (e *emitter) emit(ctx context.Context, g *graph, name string, links []string) error {
+	record := g.record(name, links)
+	if record == nil {
+		return nil
+	}
+
+	if err := e.sink.Write(ctx, record); err != nil {
+		return fmt.Errorf("failed to write record %q: %w", name, err)
+	}
+
+	e.count++
+	if e.count%progressEvery == 0 {
+		global.Logger.Info("Import progress", zap.Int("records", e.count))
+	}
+
+	return nil
+}
+
+// importXML imports a pages-articles dump in two passes: the first builds
+// the article and redirect index, the second emits the records
+func importXML(ctx context.Context, path string, sink Sink) (int, error) {
+	g := newGraph()
+
+	err := readXML(ctx, path, func(page *Page) error {
+		if page.Ns != 0 {
+			return nil
+		}
+		title := utils.NormalizeTitle(page.Title)
+		if page.Redirect != nil {
+			g.addRedirect(title, utils.NormalizeTitle(page.Redirect.Title))
+		} else {
+			g.addArticle(title)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	g.buildAliases()
+
+	global.Logger.Info("Indexed XML dump",
+		zap.Int("articles", len(g.articles)),
+		zap.Int("redirects", len(g.redirects)),
+	)
+
+	e := &emitter{sink: sink}
+	err = readXML(ctx, path, func(page *Page) error {
+		if page.Ns != 0 || page.Redirect != nil {
+			return nil
+		}
+		return e.emit(ctx, g, utils.NormalizeTitle(page.Title), ParseLinks(page.Text))
+	})
+
+	return e.count, err
+}
+
+// readXML calls fn for every page of an XML dump
+func readXML(ctx context.Context, path string, fn func(*Page) error) error {
+	file, err := Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := NewXMLReader(file)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		page, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read XML dump %s: %w", path, err)
+		}
+
+		if err := fn(page); err != nil {
+			return err
+		}
+	}
+}
+
+// importSQL imports the page/redirect/linktarget/pagelinks table dumps.
+// pagelinks rows are grouped by pl_from, which relies on the dump being
+// ordered by its primary key as the official Wikimedia dumps are.
+func importSQL(ctx context.Context, opts Options, sink Sink) (int, error) {
+	g := newGraph()
+	pages := make(map[int64]string)         // article id -> title
+	redirectPages := make(map[int64]string) // redirect page id -> title
+
+	err := readSQL(ctx, opts.Page, "page", func(_ *SQLReader, row Row) error {
+		if row.Get("page_namespace") != "0" {
+			return nil
+		}
+		id, err := row.Int("page_id")
+		if err != nil {
+			return err
+		}
+
+		title := utils.NormalizeTitle(row.Get("page_title"))
+		if row.Get("page_is_redirect") == "1" {
+			redirectPages[id] = title
+		} else {
+			pages[id] = title
+			g.addArticle(title)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if opts.Redirect != "" {
+		err := readSQL(ctx, opts.Redirect, "redirect", func(_ *SQLReader, row Row) error {
+			if interwiki := row.Get("rd_interwiki"); row.Get("rd_namespace") != "0" || (interwiki != "" && interwiki != "NULL") {
+				return nil
+			}
+			id, err := row.Int("rd_from")
+			if err != nil {
+				return err
+			}
+			if from, ok := redirectPages[id]; ok {
+				g.addRedirect(from, utils.NormalizeTitle(row.Get("rd_title")))
+			}
+			return nil
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+	redirectPages = nil
+	g.buildAliases()
+
+	global.Logger.Info("Indexed SQL dumps",
+		zap.Int("articles", len(g.articles)),
+		zap.Int("redirects", len(g.redirects)),
+	)
+
+	var targets map[int64]string
+	if opts.LinkTarget != "" {
+		targets = make(map[int64]string)
+		err := readSQL(ctx, opts.LinkTarget, "linktarget", func(_ *SQLReader, row Row) error {
+			if row.Get("lt_namespace") != "0" {
+				return nil
+			}
+			id, err := row.Int("lt_id")
+			if err != nil {
+				return err
+			}
+			targets[id] = row.Get("lt_title")
+			return nil
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	e := &emitter{sink: sink}
+	var (
+		current int64 = -1
+		links   []string
+	)
+
+	flush := func() error {
+		name, ok := pages[current]
+		if !ok {
+			return nil
+		}
+		return e.emit(ctx, g, name, links)
+	}
+
+	err = readSQL(ctx, opts.PageLinks, "pagelinks", func(r *SQLReader, row Row) error {
+		from, err := row.Int("pl_from")
+		if err != nil {
+			return err
+		}
+
+		if from != current {
+			if err := flush(); err != nil {
+				return err
+			}
+			current, links = from, links[:0]
+		}
+
+		switch {
+		case r.HasColumn("pl_title"):
+			if row.Get("pl_namespace") == "0" {
+				links = append(links, row.Get("pl_title"))
+			}
+		case targets != nil:
+			id, err := row.Int("pl_target_id")
+			if err != nil {
+				return err
+			}
+			if title, ok := targets[id]; ok {
+				links = append(links, title)
+			}
+		default:
+			return errors.New("pagelinks dump uses link target ids, a linktarget dump is required")
+		}
+		return nil
+	})
+	if err != nil {
+		return e.count, err
+	}
+
+	return e.count, flush()
+}
+
+// readSQL calls fn for every row of a table dump
+func readSQL(ctx context.Context, path, table string, fn func(*SQLReader, Row) error) error {
+	file, err := Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := NewSQLReader(file, table)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		row, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s dump %s: %w", table, path, err)
+		}
+
+		if err := fn(reader, row); err != nil {
+			return fmt.Errorf("invalid %s row in %s: %w", table, path, err)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package dump
+
+import (
+	"bufio"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// readCloser pairs a decompressing reader with the underlying file
+type readCloser struct {
+	io.Reader
+	closers []io.Closer
+}
+
+// Close closes the decompressor and the underlying file
+func (r *readCloser) Close() error {
+	var firstErr error
+	for _, c := range r.closers {
+		if err := c.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Open opens a dump file, transparently decompressing ".gz" and ".bz2" files
+func Open(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dump %s: %w", path, err)
+	}
+
+	buffered := bufio.NewReaderSize(file, 1<<20)
+
+	switch {
+	case strings.HasSuffix(path, ".gz"):
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to read gzip dump %s: %w", path, err)
+		}
+		return &readCloser{Reader: gz, closers: []io.Closer{gz, file}}, nil
+	case strings.HasSuffix(path, ".bz2"):
+		return &readCloser{Reader: bzip2.NewReader(buffered), closers: []io.Closer{file}}, nil
+	default:
+		return &readCloser{Reader: buffered, closers: []io.Closer{file}}, nil
+	}
+}
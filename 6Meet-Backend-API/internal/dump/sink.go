@@ -0,0 +1,151 @@
+package dump
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/global"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/core/cache"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/core/dto"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/core/entity"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/core/mapper"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/ports"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/common/goroutine"
+	"go.uber.org/zap"
+)
+
+const (
+	writeBatchSize  = 500             // records written to the repository per batch
+	writeMaxLatency = 5 * time.Second // longest a record waits to be written
+)
+
+// Sink receives the records produced by an import
+type Sink interface {
+	Write(ctx context.Context, record *dto.CreateUserRequest) error
+	Close() error
+}
+
+// JSONSink writes records as JSON lines to a file
+type JSONSink struct {
+	file    *os.File
+	writer  *bufio.Writer
+	encoder *json.Encoder
+}
+
+var _ Sink = (*JSONSink)(nil)
+
+// NewJSONSink creates (or truncates) the output file
+func NewJSONSink(path string) (*JSONSink, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output %s: %w", path, err)
+	}
+
+	writer := bufio.NewWriterSize(file, 1<<20)
+
+	return &JSONSink{
+		file:    file,
+		writer:  writer,
+		encoder: json.NewEncoder(writer),
+	}, nil
+}
+
+// Write appends a record to the output
+func (s *JSONSink) Write(_ context.Context, record *dto.CreateUserRequest) error {
+	return s.encoder.Encode(record)
+}
+
+// Close flushes buffered records and closes the file
+func (s *JSONSink) Close() error {
+	if err := s.writer.Flush(); err != nil {
+		s.file.Close()
+		return err
+	}
+	return s.file.Close()
+}
+
+// RepositorySink stores records through the user repository in batches
+type RepositorySink struct {
+	userRepo ports.UserRepository
+	batcher  *goroutine.Batcher[*dto.CreateUserRequest]
+	failed   int
+}
+
+var _ Sink = (*RepositorySink)(nil)
+
+// NewRepositorySink creates a sink writing to the user repository
+func NewRepositorySink(ctx context.Context, userRepo ports.UserRepository) *RepositorySink {
+	s := &RepositorySink{
+		userRepo: userRepo,
+	}
+	s.batcher = goroutine.NewBatcher(ctx, s.flush, s.onError,
+		goroutine.WithBatchSize(writeBatchSize),
+		goroutine.WithMaxLatency(writeMaxLatency),
+	)
+
+	return s
+}
+
+// Write queues a record to be stored as a user
+func (s *RepositorySink) Write(_ context.Context, record *dto.CreateUserRequest) error {
+	return s.batcher.Add(record)
+}
+
+// Close writes the queued records and reports how many could not be stored.
+// The repository itself is owned by the caller.
+func (s *RepositorySink) Close() error {
+	s.batcher.Close()
+
+	if s.failed > 0 {
+		return fmt.Errorf("failed to store %d records", s.failed)
+	}
+	return nil
+}
+
+// flush upserts the users of a batch of records by name, so importing a
+// dump again updates the users instead of duplicating them. The cached
+// copies of the written users are dropped.
+func (s *RepositorySink) flush(ctx context.Context, records []*dto.CreateUserRequest) []error {
+	users := make([]*entity.User, len(records))
+	names := make([]string, len(records))
+	for i, record := range records {
+		users[i] = mapper.ToUserEntityFromReq(record)
+		names[i] = record.Name
+	}
+
+	errs, err := s.userRepo.UpsertMany(ctx, users)
+	if err != nil {
+		errs = make([]error, len(records))
+		for i := range errs {
+			errs[i] = err
+		}
+		return errs
+	}
+
+	// Users updated by name do not learn their ID from the bulk write
+	written, err := s.userRepo.FindByNames(ctx, names)
+	if err != nil {
+		global.Logger.Error("Failed to find written users to invalidate", zap.Error(err))
+		return errs
+	}
+	ids := make([]string, len(written))
+	for i, user := range written {
+		ids[i] = user.ID
+	}
+	cache.InvalidateUsers(ctx, ids...)
+
+	return errs
+}
+
+// onError logs a record that could not be stored
+func (s *RepositorySink) onError(record *dto.CreateUserRequest, err error) {
+	s.failed++
+	global.Logger.Error("Failed to store record",
+		zap.String("name", record.Name),
+		zap.Error(err),
+	)
+}
@@ -0,0 +1,181 @@
+package dump
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Row is a single tuple of a MySQL dump INSERT statement
+type Row struct {
+	values  []string
+	columns map[string]int
+}
+
+// Get returns the raw value of a column, or "" if the column is unknown
+func (r Row) Get(column string) string {
+	idx, ok := r.columns[column]
+	if !ok || idx >= len(r.values) {
+		return ""
+	}
+	return r.values[idx]
+}
+
+// Int returns the value of a column parsed as an integer
+func (r Row) Int(column string) (int64, error) {
+	return strconv.ParseInt(r.Get(column), 10, 64)
+}
+
+// SQLReader streams rows of one table from a mysqldump file such as
+// enwiki-latest-page.sql.gz. Column positions are taken from the
+// CREATE TABLE statement, so different MediaWiki schema versions work.
+type SQLReader struct {
+	r        *bufio.Reader
+	table    string
+	columns  map[string]int
+	inCreate bool
+	rows     [][]string
+}
+
+// NewSQLReader creates a reader for the given table
+func NewSQLReader(r io.Reader, table string) *SQLReader {
+	return &SQLReader{
+		r:     bufio.NewReaderSize(r, 1<<20),
+		table: table,
+	}
+}
+
+// HasColumn reports whether the table definition contains the column.
+// It is only meaningful after the first row has been read.
+func (s *SQLReader) HasColumn(column string) bool {
+	_, ok := s.columns[column]
+	return ok
+}
+
+// Next returns the next row of the table, or io.EOF when the dump is exhausted
+func (s *SQLReader) Next() (Row, error) {
+	for len(s.rows) == 0 {
+		line, err := s.r.ReadString('\n')
+		if len(line) > 0 {
+			if perr := s.parseLine(line); perr != nil {
+				return Row{}, perr
+			}
+		}
+		if err != nil {
+			if len(s.rows) > 0 {
+				break
+			}
+			return Row{}, err
+		}
+	}
+
+	values := s.rows[0]
+	s.rows = s.rows[1:]
+
+	return Row{values: values, columns: s.columns}, nil
+}
+
+// parseLine handles table definitions and INSERT statements
+func (s *SQLReader) parseLine(line string) error {
+	createPrefix := "CREATE TABLE `" + s.table + "`"
+	insertPrefix := "INSERT INTO `" + s.table + "` VALUES "
+
+	switch {
+	case strings.HasPrefix(line, createPrefix):
+		s.inCreate = true
+		s.columns = make(map[string]int)
+	case s.inCreate:
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, ")") {
+			s.inCreate = false
+			return nil
+		}
+		if strings.HasPrefix(trimmed, "`") {
+			if end := strings.Index(trimmed[1:], "`"); end >= 0 {
+				s.columns[trimmed[1:end+1]] = len(s.columns)
+			}
+		}
+	case strings.HasPrefix(line, insertPrefix):
+		if s.columns == nil {
+			return fmt.Errorf("INSERT for table %s found before its CREATE TABLE statement", s.table)
+		}
+		rows, err := parseValues(line[len(insertPrefix):])
+		if err != nil {
+			return fmt.Errorf("failed to parse INSERT for table %s: %w", s.table, err)
+		}
+		s.rows = append(s.rows, rows...)
+	}
+
+	return nil
+}
+
+// parseValues parses the "(a,'b',NULL),(...);" tail of an INSERT statement
+func parseValues(s string) ([][]string, error) {
+	var rows [][]string
+	var row []string
+	var field strings.Builder
+	inTuple, inString := false, false
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+
+		switch {
+		case inString:
+			switch c {
+			case '\\':
+				i++
+				if i < len(s) {
+					field.WriteByte(unescape(s[i]))
+				}
+			case '\'':
+				inString = false
+			default:
+				field.WriteByte(c)
+			}
+		case !inTuple:
+			if c == '(' {
+				inTuple = true
+				row = nil
+			}
+		case c == '\'':
+			inString = true
+		case c == ',':
+			row = append(row, field.String())
+			field.Reset()
+		case c == ')':
+			row = append(row, field.String())
+			field.Reset()
+			rows = append(rows, row)
+			inTuple = false
+		default:
+			field.WriteByte(c)
+		}
+	}
+
+	if inTuple || inString {
+		return nil, errors.New("unterminated tuple")
+	}
+
+	return rows, nil
+}
+
+// unescape maps a MySQL backslash escape to its byte value
+func unescape(c byte) byte {
+	switch c {
+	case '0':
+		return 0
+	case 'n':
+		return '\n'
+	case 'r':
+		return '\r'
+	case 't':
+		return '\t'
+	case 'Z':
+		return 0x1a
+	default:
+		return c
+	}
+}
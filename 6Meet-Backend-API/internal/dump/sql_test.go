@@ -0,0 +1,98 @@
+package dump
+
+import (
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const pageDump = "-- MySQL dump\n" +
+	"CREATE TABLE `page` (\n" +
+	"  `page_id` int(8) unsigned NOT NULL AUTO_INCREMENT,\n" +
+	"  `page_namespace` int(11) NOT NULL DEFAULT 0,\n" +
+	"  `page_title` varbinary(255) NOT NULL DEFAULT '',\n" +
+	"  `page_is_redirect` tinyint(1) unsigned NOT NULL DEFAULT 0,\n" +
+	"  PRIMARY KEY (`page_id`)\n" +
+	") ENGINE=InnoDB;\n" +
+	"INSERT INTO `page` VALUES (1,0,'Go_(language)',0),(2,0,'It\\'s,here',1);\n" +
+	"INSERT INTO `other` VALUES (9,9,'skipped',0);\n" +
+	"INSERT INTO `page` VALUES (3,14,'Line\\nbreak',0);\n"
+
+func TestSQLReaderRows(t *testing.T) {
+	r := NewSQLReader(strings.NewReader(pageDump), "page")
+
+	want := []struct {
+		id       int64
+		title    string
+		redirect string
+	}{
+		{1, "Go_(language)", "0"},
+		{2, "It's,here", "1"},
+		{3, "Line\nbreak", "0"},
+	}
+
+	for _, w := range want {
+		row, err := r.Next()
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		id, err := row.Int("page_id")
+		if err != nil || id != w.id {
+			t.Errorf("page_id = %d, %v; want %d", id, err, w.id)
+		}
+		if got := row.Get("page_title"); got != w.title {
+			t.Errorf("page_title = %q; want %q", got, w.title)
+		}
+		if got := row.Get("page_is_redirect"); got != w.redirect {
+			t.Errorf("page_is_redirect = %q; want %q", got, w.redirect)
+		}
+	}
+
+	if !r.HasColumn("page_namespace") || r.HasColumn("missing") {
+		t.Error("HasColumn does not reflect the table definition")
+	}
+	if got := (Row{}).Get("page_title"); got != "" {
+		t.Errorf("Get on an empty row = %q; want empty", got)
+	}
+	if _, err := r.Next(); !errors.Is(err, io.EOF) {
+		t.Errorf("Next() after last row error = %v; want io.EOF", err)
+	}
+}
+
+func TestSQLReaderInsertBeforeCreate(t *testing.T) {
+	r := NewSQLReader(strings.NewReader("INSERT INTO `page` VALUES (1);\n"), "page")
+	if _, err := r.Next(); err == nil || errors.Is(err, io.EOF) {
+		t.Errorf("Next() error = %v; want a missing CREATE TABLE error", err)
+	}
+}
+
+func TestParseValues(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    [][]string
+		wantErr bool
+	}{
+		{name: "single", input: "(1,'a');", want: [][]string{{"1", "a"}}},
+		{name: "multiple", input: "(1,'a'),(2,'b');", want: [][]string{{"1", "a"}, {"2", "b"}}},
+		{name: "escapes", input: `(1,'a\'b\\c\td');`, want: [][]string{{"1", "a'b\\c\td"}}},
+		{name: "separators in strings", input: "(1,'a,(b)');", want: [][]string{{"1", "a,(b)"}}},
+		{name: "empty string", input: "(1,'');", want: [][]string{{"1", ""}}},
+		{name: "unterminated tuple", input: "(1,'a'", wantErr: true},
+		{name: "unterminated string", input: "(1,'a);", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseValues(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseValues() error = %v; wantErr %v", err, tt.wantErr)
+			}
+			if !slices.EqualFunc(got, tt.want, slices.Equal[[]string]) {
+				t.Errorf("parseValues() = %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
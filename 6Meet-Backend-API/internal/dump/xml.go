@@ -0,0 +1,90 @@
+package dump
+
+import (
+	"encoding/xml"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Page is a single <page> element of a pages-articles XML dump
+type Page struct {
+	Title    string `xml:"title"`
+	Ns       int    `xml:"ns"`
+	Redirect *struct {
+		Title string `xml:"title,attr"`
+	} `xml:"redirect"`
+	Text string `xml:"revision>text"`
+}
+
+// XMLReader streams pages from a pages-articles XML dump
+type XMLReader struct {
+	decoder *xml.Decoder
+}
+
+// NewXMLReader creates a reader over an XML dump
+func NewXMLReader(r io.Reader) *XMLReader {
+	return &XMLReader{decoder: xml.NewDecoder(r)}
+}
+
+// Next returns the next page, or io.EOF when the dump is exhausted
+func (x *XMLReader) Next() (*Page, error) {
+	for {
+		token, err := x.decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "page" {
+			continue
+		}
+
+		var page Page
+		if err := x.decoder.DecodeElement(&page, &start); err != nil {
+			return nil, err
+		}
+		return &page, nil
+	}
+}
+
+var (
+	// linkPattern captures the target of [[Target]], [[Target|label]] and [[Target#Section]]
+	linkPattern = regexp.MustCompile(`\[\[([^\[\]|#]+)`)
+
+	// interwikiPattern matches language and project prefixes such as "fr" or "wikt"
+	interwikiPattern = regexp.MustCompile(`^[a-z][a-z-]{1,11}$`)
+
+	// namespaces lists the prefixes of non-article links
+	namespaces = map[string]bool{
+		"media": true, "special": true, "talk": true, "user": true, "user talk": true,
+		"wikipedia": true, "wp": true, "project": true, "file": true, "image": true,
+		"mediawiki": true, "template": true, "help": true, "category": true,
+		"portal": true, "draft": true, "module": true, "timedtext": true,
+		"wikipedia talk": true, "file talk": true, "template talk": true,
+		"help talk": true, "category talk": true, "portal talk": true,
+		"draft talk": true, "module talk": true,
+	}
+)
+
+// ParseLinks extracts the article link targets from wikitext
+func ParseLinks(text string) []string {
+	var links []string
+
+	for _, match := range linkPattern.FindAllStringSubmatch(text, -1) {
+		target := strings.TrimPrefix(strings.TrimSpace(match[1]), ":")
+		if target == "" {
+			continue
+		}
+
+		if prefix, _, ok := strings.Cut(target, ":"); ok {
+			if interwikiPattern.MatchString(prefix) || namespaces[strings.ToLower(strings.TrimSpace(prefix))] {
+				continue
+			}
+		}
+
+		links = append(links, target)
+	}
+
+	return links
+}
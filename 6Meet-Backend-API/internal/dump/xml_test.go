@@ -0,0 +1,75 @@
+package dump
+
+import (
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const articlesDump = `<mediawiki>
+  <siteinfo><sitename>Wikipedia</sitename></siteinfo>
+  <page>
+    <title>Go (language)</title>
+    <ns>0</ns>
+    <revision><text>Created at [[Google]] by [[Ken Thompson|Ken]].</text></revision>
+  </page>
+  <page>
+    <title>Golang</title>
+    <ns>0</ns>
+    <redirect title="Go (language)" />
+    <revision><text>#REDIRECT [[Go (language)]]</text></revision>
+  </page>
+</mediawiki>`
+
+func TestXMLReaderPages(t *testing.T) {
+	r := NewXMLReader(strings.NewReader(articlesDump))
+
+	page, err := r.Next()
+	if err != nil {
+		t.Fatalf("Next() error = %v", err)
+	}
+	if page.Title != "Go (language)" || page.Ns != 0 || page.Redirect != nil {
+		t.Errorf("first page = %+v; want the Go (language) article", page)
+	}
+	if !strings.Contains(page.Text, "[[Google]]") {
+		t.Errorf("first page text = %q; want the revision text", page.Text)
+	}
+
+	page, err = r.Next()
+	if err != nil {
+		t.Fatalf("Next() error = %v", err)
+	}
+	if page.Redirect == nil || page.Redirect.Title != "Go (language)" {
+		t.Errorf("second page redirect = %+v; want Go (language)", page.Redirect)
+	}
+
+	if _, err := r.Next(); !errors.Is(err, io.EOF) {
+		t.Errorf("Next() after last page error = %v; want io.EOF", err)
+	}
+}
+
+func TestParseLinks(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "plain", text: "[[Go]] and [[Rust]]", want: []string{"Go", "Rust"}},
+		{name: "label and section", text: "[[Go|the language]] [[Rust#History]]", want: []string{"Go", "Rust"}},
+		{name: "leading colon", text: "[[:Go]]", want: []string{"Go"}},
+		{name: "namespaces", text: "[[File:Go.png]] [[Category:Languages]] [[Help talk:Links]]", want: nil},
+		{name: "interwiki", text: "[[fr:Go]] [[wikt:go]]", want: nil},
+		{name: "colon in title", text: "[[Star Wars: A New Hope]]", want: []string{"Star Wars: A New Hope"}},
+		{name: "empty", text: "[[ ]] [[|label]]", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseLinks(tt.text); !slices.Equal(got, tt.want) {
+				t.Errorf("ParseLinks(%q) = %q; want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
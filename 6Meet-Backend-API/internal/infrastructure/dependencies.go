@@ -1,25 +1,30 @@
 package infrastructure
 
 import (
+	"context"
+
 	"github.com/huynhanx03/6Meet/6Meet-Backend-API/global"
 	db "github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/adapters/driven/db"
 	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/adapters/driver/http"
 	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/core/service"
 )
 
-// InitializeServer wires up all dependencies and returns the Server
-func InitializeServer() *Server {
+// InitializeServer wires up all dependencies and returns the Server.
+// Background work started by the services stops when ctx is done.
+func InitializeServer(ctx context.Context) *Server {
 	// Initialize repositories
 	userRepo := db.NewUserRepository(global.MongoDB.DB)
 
 	// Initialize services
-	userService := service.NewUserService(userRepo)
+	userService := service.NewUserService(userRepo, db.NewUnitOfWork(global.MongoDB))
+	crawlService := service.NewCrawlService(ctx, userRepo)
 
 	// Initialize controllers
 	userHandler := http.NewUserHandler(userService)
+	crawlHandler := http.NewCrawlHandler(crawlService)
 
 	// Create router group with dependencies
-	routerGroup := NewRouterGroup(userHandler)
+	routerGroup := NewRouterGroup(userHandler, crawlHandler)
 
 	// Create Gin engine
 	engine := NewEngine(routerGroup)
@@ -0,0 +1,57 @@
+package infrastructure
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/global"
+	db "github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/adapters/driven/db"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/dump"
+	"go.uber.org/zap"
+)
+
+// RunImport builds the graph from local Wikipedia dumps. Records are
+// written to MongoDB when toMongo is set, otherwise as JSON lines to output.
+func RunImport(opts dump.Options, output string, toMongo bool) error {
+	LoadConfig()
+	SetupLogger()
+
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var (
+		sink dump.Sink
+		err  error
+	)
+	if toMongo {
+		SetupMongoDB()
+		defer global.MongoDB.Close()
+		// Written users are dropped from the API's cache
+		SetupRedis()
+		sink = dump.NewRepositorySink(ctx, db.NewUserRepository(global.MongoDB.DB))
+	} else {
+		sink, err = dump.NewJSONSink(output)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = dump.Import(ctx, opts, sink)
+
+	// Close flushes buffered records, so its error matters as much as the import's
+	if closeErr := sink.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		global.Logger.Error("Import failed", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/global"
+	db "github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/adapters/driven/db"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/database/mongodb"
+	"go.uber.org/zap"
+)
+
+// MigrateMongoDB applies the pending schema migrations
+func MigrateMongoDB(ctx context.Context) error {
+	migrator, err := mongodb.NewMigrator(global.MongoDB.DB, db.Migrations()...)
+	if err != nil {
+		return err
+	}
+
+	applied, err := migrator.Up(ctx)
+	logMigrations("Applied migration", applied)
+	return err
+}
+
+// RunMigrate applies the pending migrations, or reverts the last down
+// migrations, or prints the migration status
+func RunMigrate(down int, status bool) error {
+	LoadConfig()
+	SetupLogger()
+	SetupMongoDB()
+	defer global.MongoDB.Close()
+
+	ctx := context.Background()
+
+	migrator, err := mongodb.NewMigrator(global.MongoDB.DB, db.Migrations()...)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case status:
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%4d  %-25s  %s\n", s.Version, applied, s.Description)
+		}
+		return nil
+	case down > 0:
+		reverted, err := migrator.Down(ctx, down)
+		logMigrations("Reverted migration", reverted)
+		return err
+	default:
+		applied, err := migrator.Up(ctx)
+		logMigrations("Applied migration", applied)
+		return err
+	}
+}
+
+// logMigrations logs each migration with the given message
+func logMigrations(msg string, migrations []mongodb.Migration) {
+	for _, migration := range migrations {
+		global.Logger.Info(msg,
+			zap.Int64("version", migration.Version),
+			zap.String("description", migration.Description),
+		)
+	}
+}
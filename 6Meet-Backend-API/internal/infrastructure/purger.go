@@ -0,0 +1,23 @@
+package infrastructure
+
+import (
+	"context"
+
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/global"
+	db "github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/adapters/driven/db"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/core/service"
+)
+
+// StartPurger starts the purger of deleted users in the background if enabled
+func StartPurger(ctx context.Context) {
+	if !global.Config.Purge.Enabled {
+		return
+	}
+
+	userRepo := db.NewUserRepository(global.MongoDB.DB)
+	purger := service.NewPurger(userRepo, global.Config.Purge)
+
+	go purger.Run(ctx)
+
+	global.Logger.Info("Deleted user purger started")
+}
@@ -0,0 +1,23 @@
+package infrastructure
+
+import (
+	"context"
+
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/global"
+	db "github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/adapters/driven/db"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/crawl"
+)
+
+// StartRefresher starts the stale page refresher in the background if enabled
+func StartRefresher(ctx context.Context) {
+	if !global.Config.Crawler.Refresh.Enabled {
+		return
+	}
+
+	userRepo := db.NewUserRepository(global.MongoDB.DB)
+	refresher := crawl.NewRefresher(userRepo)
+
+	go refresher.Run(ctx)
+
+	global.Logger.Info("Stale page refresher started")
+}
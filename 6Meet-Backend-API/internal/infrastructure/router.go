@@ -6,20 +6,24 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/huynhanx03/6Meet/6Meet-Backend-API/global"
 	driverHttp "github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/adapters/driver/http"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/common/goroutine"
 	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/common/http/middlewares"
 )
 
 // RouterGroup contains all routes
 type RouterGroup struct {
-	UserHandler driverHttp.UserHandler
+	UserHandler  driverHttp.UserHandler
+	CrawlHandler driverHttp.CrawlHandler
 }
 
 // NewRouterGroup creates a new RouterGroup
 func NewRouterGroup(
 	userHandler driverHttp.UserHandler,
+	crawlHandler driverHttp.CrawlHandler,
 ) *RouterGroup {
 	return &RouterGroup{
-		UserHandler: userHandler,
+		UserHandler:  userHandler,
+		CrawlHandler: crawlHandler,
 	}
 }
 
@@ -31,11 +35,27 @@ func (rg *RouterGroup) registerRoutes(r *gin.Engine) {
 	users := api.Group("/users")
 	{
 		users.POST("/search", rg.UserHandler.Find)
+		users.GET("/name/:name", rg.UserHandler.GetByName)
 		users.GET("/:id", rg.UserHandler.Get)
 
 		users.POST("", rg.UserHandler.Create)
 		users.PUT("/:id", rg.UserHandler.Update)
 		users.DELETE("/:id", rg.UserHandler.Delete)
+		// Restore is public like Delete, which it undoes; only purging is final
+		users.POST("/:id/restore", rg.UserHandler.Restore)
+		users.DELETE("/:id/purge", middlewares.AdminMiddleware(global.Config.Server.AdminToken), rg.UserHandler.Purge)
+	}
+
+	// Crawl job routes
+	crawls := api.Group("/crawls")
+	{
+		crawls.GET("", rg.CrawlHandler.List)
+		crawls.GET("/:id", rg.CrawlHandler.Get)
+
+		admin := crawls.Group("", middlewares.AdminMiddleware(global.Config.Server.AdminToken))
+		admin.POST("", rg.CrawlHandler.Create)
+		admin.PATCH("/:id", rg.CrawlHandler.Resize)
+		admin.DELETE("/:id", rg.CrawlHandler.Cancel)
 	}
 }
 
@@ -47,6 +67,13 @@ func Ping(c *gin.Context) {
 	})
 }
 
+// Metrics reports the live statistics of the named worker pools
+func Metrics(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"worker_pools": goroutine.AllStats(),
+	})
+}
+
 // NewEngine creates and configures the Gin engine
 func NewEngine(routerGroup *RouterGroup) *gin.Engine {
 	if global.Config.Server.Mode != "release" {
@@ -59,6 +86,7 @@ func NewEngine(routerGroup *RouterGroup) *gin.Engine {
 	r.Use(middlewares.CORSMiddleware)
 
 	r.GET("/ping", Ping)
+	r.GET("/metrics", Metrics)
 
 	// Register routes
 	routerGroup.registerRoutes(r)
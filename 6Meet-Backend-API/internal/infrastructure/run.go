@@ -1,14 +1,31 @@
 package infrastructure
 
+import (
+	"context"
+
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/global"
+)
+
 func Run() error {
 	LoadConfig()
 
 	SetupLogger()
 	SetupMongoDB()
 	SetupRedis()
-	server := InitializeServer()
 
-	// LoadData()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if global.Config.MongoDB.Migrate {
+		if err := MigrateMongoDB(ctx); err != nil {
+			return err
+		}
+	}
+
+	server := InitializeServer(ctx)
+
+	StartRefresher(ctx)
+	StartPurger(ctx)
 
 	return server.Run()
 }
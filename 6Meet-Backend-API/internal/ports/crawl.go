@@ -0,0 +1,17 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/core/dto"
+)
+
+// CrawlService defines the interface for crawl job service
+type CrawlService interface {
+	List(ctx context.Context) ([]*dto.CrawlJobResponse, error)
+	Get(ctx context.Context, id string) (*dto.CrawlJobResponse, error)
+
+	Create(ctx context.Context, req *dto.CreateCrawlRequest) (*dto.CrawlJobResponse, error)
+	Resize(ctx context.Context, id string, req *dto.ResizeCrawlRequest) (*dto.CrawlJobResponse, error)
+	Cancel(ctx context.Context, id string) (*dto.CrawlJobResponse, error)
+}
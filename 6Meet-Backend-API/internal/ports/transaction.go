@@ -0,0 +1,10 @@
+package ports
+
+import "context"
+
+// UnitOfWork runs a group of repository calls atomically. Repositories must
+// be called with the ctx passed to fn to take part, and fn may run more than
+// once when the transaction is retried.
+type UnitOfWork interface {
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
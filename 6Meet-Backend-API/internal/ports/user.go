@@ -2,6 +2,7 @@ package ports
 
 import (
 	"context"
+	"time"
 
 	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/core/dto"
 	"github.com/huynhanx03/6Meet/6Meet-Backend-API/internal/core/entity"
@@ -12,18 +13,29 @@ import (
 type UserRepository interface {
 	Find(ctx context.Context, opts *d.QueryOptions) (*d.Paginated[*entity.User], error)
 	Get(ctx context.Context, id string) (*entity.User, error)
+	GetByName(ctx context.Context, name string) (*entity.User, error)
+	FindByNames(ctx context.Context, names []string) ([]*entity.User, error)
+	FindStale(ctx context.Context, before time.Time, after *entity.User, limit int) ([]*entity.User, error)
 	Create(ctx context.Context, user *entity.User) error
+	UpsertMany(ctx context.Context, users []*entity.User) ([]error, error)
 	Update(ctx context.Context, id string, user *entity.User) error
-	Delete(ctx context.Context, id string) error
+	Touch(ctx context.Context, ids []string) error
+	Delete(ctx context.Context, id string, version *int64) error
+	Restore(ctx context.Context, id string) error
+	Purge(ctx context.Context, id string) error
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
 	Exists(ctx context.Context, id string) (bool, error)
 }
 
 // UserService defines the interface for user service
 type UserService interface {
-	Find(ctx context.Context, opts *d.QueryOptions) (*d.Paginated[*dto.UserResponse], error)
+	Find(ctx context.Context, opts *d.QueryOptions) (*d.Paginated[*dto.UserFieldsResponse], error)
 	Get(ctx context.Context, id string) (*dto.UserResponse, error)
+	GetByName(ctx context.Context, name string) (*dto.UserResponse, error)
 
 	Create(ctx context.Context, req *dto.CreateUserRequest) (*dto.UserResponse, error)
 	Update(ctx context.Context, id string, req *dto.UpdateUserRequest) (*dto.UserResponse, error)
-	Delete(ctx context.Context, id string) error
+	Delete(ctx context.Context, id string, version *int64) error
+	Restore(ctx context.Context, id string) (*dto.UserResponse, error)
+	Purge(ctx context.Context, id string) error
 }
@@ -17,6 +17,11 @@ func (e *AppError) Error() string {
 	return fmt.Sprintf("Code: %d, Message: %s, RootCause: %v", e.Code, e.Message, e.RootCause)
 }
 
+// Unwrap returns the root cause, so callers can still match it with errors.Is
+func (e *AppError) Unwrap() error {
+	return e.RootCause
+}
+
 // New creates a new AppError
 func New(code int, message string, httpStatus int, rootCause error) *AppError {
 	return &AppError{
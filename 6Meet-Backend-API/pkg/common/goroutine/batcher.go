@@ -0,0 +1,213 @@
+package goroutine
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// FlushFunc writes a batch of items. It returns nil when every item was
+// written, or one error per item with nil entries for the items that
+// succeeded. Items must not be retained after it returns.
+type FlushFunc[T any] func(ctx context.Context, items []T) []error
+
+// BatcherOption represents configuration options for Batcher
+type BatcherOption func(*BatcherConfig)
+
+// BatcherConfig holds configuration for Batcher
+type BatcherConfig struct {
+	Size         int
+	MaxLatency   time.Duration
+	CloseTimeout time.Duration
+}
+
+// WithBatchSize sets the number of items that triggers a flush
+func WithBatchSize(n int) BatcherOption {
+	return func(c *BatcherConfig) {
+		c.Size = n
+	}
+}
+
+// WithMaxLatency sets how long an item may wait before its batch is flushed,
+// even when the batch is not full. Zero flushes on size only.
+func WithMaxLatency(d time.Duration) BatcherOption {
+	return func(c *BatcherConfig) {
+		c.MaxLatency = d
+	}
+}
+
+// WithCloseTimeout bounds the final flush of Close. That flush outlives the
+// batcher context, so a cancelled caller still writes what it had queued.
+func WithCloseTimeout(d time.Duration) BatcherOption {
+	return func(c *BatcherConfig) {
+		c.CloseTimeout = d
+	}
+}
+
+// Batcher collects items and flushes them in batches, by size or once the
+// oldest item has waited for the maximum latency
+type Batcher[T any] struct {
+	ctx          context.Context
+	flush        FlushFunc[T]
+	onError      func(item T, err error)
+	size         int
+	maxLatency   time.Duration
+	closeTimeout time.Duration
+
+	items    chan T
+	flushReq chan chan struct{}
+	done     chan struct{}
+
+	mu     sync.Mutex     // guards closed
+	closed bool           // Close has begun
+	adding sync.WaitGroup // Add calls in progress
+}
+
+// NewBatcher creates a batcher and starts its flush loop. onError receives
+// every item the flush function failed to write and may be nil.
+func NewBatcher[T any](ctx context.Context, flush FlushFunc[T], onError func(item T, err error), opts ...BatcherOption) *Batcher[T] {
+	config := &BatcherConfig{
+		Size:         100,
+		MaxLatency:   time.Second,
+		CloseTimeout: 30 * time.Second,
+	}
+
+	for _, opt := range opts {
+		opt(config)
+	}
+	config.Size = max(config.Size, 1)
+
+	b := &Batcher[T]{
+		ctx:          ctx,
+		flush:        flush,
+		onError:      onError,
+		size:         config.Size,
+		maxLatency:   config.MaxLatency,
+		closeTimeout: config.CloseTimeout,
+		items:        make(chan T, config.Size),
+		flushReq:     make(chan chan struct{}),
+		done:         make(chan struct{}),
+	}
+	go b.run()
+
+	return b
+}
+
+// Add queues an item. It blocks while the flush loop is behind and fails
+// once the batcher is closed or its context is done.
+func (b *Batcher[T]) Add(item T) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return ErrBatcherClosed
+	}
+	b.adding.Add(1)
+	b.mu.Unlock()
+	defer b.adding.Done()
+
+	select {
+	case b.items <- item:
+		return nil
+	case <-b.ctx.Done():
+		return b.ctx.Err()
+	}
+}
+
+// Flush writes the queued items now and waits until they are written
+func (b *Batcher[T]) Flush() {
+	ack := make(chan struct{})
+
+	select {
+	case b.flushReq <- ack:
+		<-ack
+	case <-b.done:
+	}
+}
+
+// Close stops accepting items, flushes the remaining ones and waits for the
+// flush loop to exit. The remaining items are written even when the batcher
+// context is done, within the close timeout. It is safe to call more than once.
+func (b *Batcher[T]) Close() {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		<-b.done
+		return
+	}
+	b.closed = true
+	b.mu.Unlock()
+
+	b.adding.Wait()
+	close(b.items)
+	<-b.done
+}
+
+// run collects items and flushes them by size, latency or on request
+func (b *Batcher[T]) run() {
+	defer close(b.done)
+
+	var (
+		batch  = make([]T, 0, b.size)
+		timer  *time.Timer
+		timerC <-chan time.Time
+	)
+
+	flush := func(ctx context.Context) {
+		if timer != nil {
+			timer.Stop()
+			timer, timerC = nil, nil
+		}
+		if len(batch) == 0 {
+			return
+		}
+		b.write(ctx, batch)
+		batch = make([]T, 0, b.size)
+	}
+
+	for {
+		select {
+		case item, ok := <-b.items:
+			if !ok {
+				ctx, cancel := context.WithTimeout(context.WithoutCancel(b.ctx), b.closeTimeout)
+				flush(ctx)
+				cancel()
+				return
+			}
+
+			batch = append(batch, item)
+			if len(batch) == 1 && b.maxLatency > 0 {
+				timer = time.NewTimer(b.maxLatency)
+				timerC = timer.C
+			}
+			if len(batch) >= b.size {
+				flush(b.ctx)
+			}
+		case <-timerC:
+			flush(b.ctx)
+		case ack := <-b.flushReq:
+			// Include the items Add queued before Flush was called
+			for len(b.items) > 0 {
+				batch = append(batch, <-b.items)
+				if len(batch) >= b.size {
+					flush(b.ctx)
+				}
+			}
+			flush(b.ctx)
+			close(ack)
+		}
+	}
+}
+
+// write flushes a batch and reports the items that failed
+func (b *Batcher[T]) write(ctx context.Context, batch []T) {
+	errs := b.flush(ctx, batch)
+	if b.onError == nil {
+		return
+	}
+
+	for i, err := range errs {
+		if err != nil && i < len(batch) {
+			b.onError(batch[i], err)
+		}
+	}
+}
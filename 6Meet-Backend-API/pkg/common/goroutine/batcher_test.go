@@ -0,0 +1,182 @@
+package goroutine
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flushRecorder records the batches passed to a flush function
+type flushRecorder struct {
+	mu      sync.Mutex
+	batches [][]int
+	fail    func(item int) error // per-item error, nil writes every item
+}
+
+func (r *flushRecorder) flush(ctx context.Context, items []int) []error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.batches = append(r.batches, slices.Clone(items))
+	if r.fail == nil {
+		return nil
+	}
+
+	errs := make([]error, len(items))
+	for i, item := range items {
+		errs[i] = r.fail(item)
+	}
+	return errs
+}
+
+func (r *flushRecorder) snapshot() [][]int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.batches)
+}
+
+func TestBatcherFlushesBySize(t *testing.T) {
+	var r flushRecorder
+	b := NewBatcher(context.Background(), r.flush, nil, WithBatchSize(3), WithMaxLatency(0))
+
+	for i := 1; i <= 7; i++ {
+		if err := b.Add(i); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+	eventually(t, "two full batches", func() bool { return len(r.snapshot()) == 2 })
+
+	b.Close()
+
+	want := [][]int{{1, 2, 3}, {4, 5, 6}, {7}}
+	if got := r.snapshot(); !slices.EqualFunc(got, want, slices.Equal[[]int]) {
+		t.Errorf("batches = %v; want %v", got, want)
+	}
+}
+
+func TestBatcherFlushesByLatency(t *testing.T) {
+	var r flushRecorder
+	b := NewBatcher(context.Background(), r.flush, nil, WithBatchSize(100), WithMaxLatency(10*time.Millisecond))
+	defer b.Close()
+
+	if err := b.Add(1); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	eventually(t, "the partial batch to be flushed", func() bool { return len(r.snapshot()) == 1 })
+}
+
+func TestBatcherFlush(t *testing.T) {
+	var r flushRecorder
+	b := NewBatcher(context.Background(), r.flush, nil, WithBatchSize(2), WithMaxLatency(0))
+	defer b.Close()
+
+	for i := 1; i <= 3; i++ {
+		if err := b.Add(i); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+	b.Flush()
+
+	var flushed []int
+	for _, batch := range r.snapshot() {
+		flushed = append(flushed, batch...)
+	}
+	if want := []int{1, 2, 3}; !slices.Equal(flushed, want) {
+		t.Errorf("flushed %v; want every added item %v", flushed, want)
+	}
+}
+
+func TestBatcherReportsFailedItems(t *testing.T) {
+	errOdd := errors.New("odd")
+	r := flushRecorder{fail: func(item int) error {
+		if item%2 == 1 {
+			return errOdd
+		}
+		return nil
+	}}
+
+	var failed []int
+	onError := func(item int, err error) {
+		if errors.Is(err, errOdd) {
+			failed = append(failed, item)
+		}
+	}
+
+	b := NewBatcher(context.Background(), r.flush, onError, WithBatchSize(10))
+	for i := 1; i <= 5; i++ {
+		if err := b.Add(i); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+	b.Close()
+
+	if want := []int{1, 3, 5}; !slices.Equal(failed, want) {
+		t.Errorf("failed items = %v; want %v", failed, want)
+	}
+}
+
+func TestBatcherClose(t *testing.T) {
+	var r flushRecorder
+	b := NewBatcher(context.Background(), r.flush, nil)
+
+	var (
+		wg       sync.WaitGroup
+		accepted atomic.Int64
+	)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if err := b.Add(j); err != nil {
+					if !errors.Is(err, ErrBatcherClosed) {
+						t.Errorf("Add() error = %v", err)
+					}
+					return
+				}
+				accepted.Add(1)
+			}
+		}()
+	}
+
+	b.Close()
+	b.Close()
+	wg.Wait()
+
+	var flushed int64
+	for _, batch := range r.snapshot() {
+		flushed += int64(len(batch))
+	}
+	if flushed != accepted.Load() {
+		t.Errorf("flushed %d items; want every one of the %d accepted", flushed, accepted.Load())
+	}
+
+	if err := b.Add(1); !errors.Is(err, ErrBatcherClosed) {
+		t.Errorf("Add() after Close error = %v; want ErrBatcherClosed", err)
+	}
+}
+
+func TestBatcherCloseAfterCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var flushErr error
+	flush := func(ctx context.Context, items []int) []error {
+		flushErr = ctx.Err()
+		return nil
+	}
+	b := NewBatcher(ctx, flush, nil, WithBatchSize(10), WithMaxLatency(0))
+	if err := b.Add(1); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	cancel()
+	b.Close()
+
+	if flushErr != nil {
+		t.Errorf("final flush context error = %v; want the queued items written", flushErr)
+	}
+}
@@ -0,0 +1,41 @@
+package goroutine
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrPoolClosed is returned when submitting to a pool that no longer accepts tasks
+var ErrPoolClosed = errors.New("worker pool is closed or context cancelled")
+
+// TaskError wraps the final error of a task with its identity and attempt count
+type TaskError struct {
+	TaskID   string
+	Attempts int
+	Err      error
+}
+
+// Error implements the error interface
+func (e *TaskError) Error() string {
+	return fmt.Sprintf("task %s failed after %d attempt(s): %v", e.TaskID, e.Attempts, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *TaskError) Unwrap() error {
+	return e.Err
+}
+
+// PanicError is returned for a task whose Process panicked
+type PanicError struct {
+	TaskID string
+	Value  any    // value passed to panic
+	Stack  []byte // stack trace of the panicking goroutine
+}
+
+// Error implements the error interface
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("task %s panicked: %v", e.TaskID, e.Value)
+}
+
+// ErrBatcherClosed is returned when adding to a batcher that has been closed
+var ErrBatcherClosed = errors.New("batcher is closed")
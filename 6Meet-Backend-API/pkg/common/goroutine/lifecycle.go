@@ -0,0 +1,27 @@
+package goroutine
+
+// PoolState is the lifecycle state of a worker pool
+type PoolState int32
+
+const (
+	// PoolRunning accepts and processes tasks
+	PoolRunning PoolState = iota
+	// PoolDraining rejects new tasks and finishes the accepted ones
+	PoolDraining
+	// PoolStopped has finished every task and closed its channels
+	PoolStopped
+)
+
+// String returns the name of the state
+func (s PoolState) String() string {
+	switch s {
+	case PoolRunning:
+		return "running"
+	case PoolDraining:
+		return "draining"
+	case PoolStopped:
+		return "stopped"
+	default:
+		return "unknown"
+	}
+}
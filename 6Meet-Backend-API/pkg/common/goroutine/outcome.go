@@ -0,0 +1,132 @@
+package goroutine
+
+import (
+	"context"
+	"sort"
+)
+
+// Outcome is the result of a task together with the identity of the task
+// that produced it
+type Outcome[T any] struct {
+	Seq    uint64 // submission order of the task, starting at 1
+	TaskID string // identity of the task, see Identifier
+	Value  T      // result of the task, zero when Err is set
+	Err    error  // final error of the task
+}
+
+// Future is a handle to the result of a task submitted with SubmitFuture
+type Future[T any] struct {
+	id    string
+	done  chan struct{}
+	value T
+	err   error
+}
+
+// newFuture creates an unresolved future for a task
+func newFuture[T any](id string) *Future[T] {
+	return &Future[T]{
+		id:   id,
+		done: make(chan struct{}),
+	}
+}
+
+// TaskID returns the identity of the task
+func (f *Future[T]) TaskID() string {
+	return f.id
+}
+
+// Done returns a channel closed once the task has finished
+func (f *Future[T]) Done() <-chan struct{} {
+	return f.done
+}
+
+// Await blocks until the task has finished or ctx is done
+func (f *Future[T]) Await(ctx context.Context) (T, error) {
+	select {
+	case <-f.done:
+		return f.value, f.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// resolve records the result of the task and wakes the waiters
+func (f *Future[T]) resolve(value T, err error) {
+	f.value = value
+	f.err = err
+	close(f.done)
+}
+
+// job is a submitted task with the bookkeeping needed to route its result
+type job[T any] struct {
+	task   Task[T]
+	seq    uint64     // submission order, 0 for futures
+	future *Future[T] // set when the task was submitted with SubmitFuture
+}
+
+// send delivers v on ch. Once ctx is done v is only delivered if the
+// channel has room, so a stopped consumer never blocks the pool.
+func send[V any](ctx context.Context, ch chan<- V, v V) bool {
+	select {
+	case ch <- v:
+		return true
+	default:
+	}
+
+	select {
+	case ch <- v:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// emitter forwards finished tasks to the output channels, re-sequencing
+// them to submission order when ordered results are enabled
+func (wp *WorkerPool[T]) emitter() {
+	defer wp.emitWg.Done()
+
+	next := uint64(1)
+	pending := make(map[uint64]Outcome[T])
+
+	for outcome := range wp.finished {
+		if !wp.ordered {
+			wp.emit(outcome)
+			continue
+		}
+
+		pending[outcome.Seq] = outcome
+		for {
+			outcome, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			wp.emit(outcome)
+		}
+	}
+
+	// Tasks dropped on cancellation leave gaps; flush what is left in order
+	seqs := make([]uint64, 0, len(pending))
+	for seq := range pending {
+		seqs = append(seqs, seq)
+	}
+	sort.Slice(seqs, func(i, k int) bool { return seqs[i] < seqs[k] })
+	for _, seq := range seqs {
+		wp.emit(pending[seq])
+	}
+}
+
+// emit delivers an outcome as an envelope or on the result and error channels
+func (wp *WorkerPool[T]) emit(outcome Outcome[T]) {
+	switch {
+	case wp.envelopes:
+		send(wp.ctx, wp.outcomes, outcome)
+	case outcome.Err != nil:
+		send(wp.ctx, wp.errors, outcome.Err)
+	default:
+		send(wp.ctx, wp.results, outcome.Value)
+	}
+}
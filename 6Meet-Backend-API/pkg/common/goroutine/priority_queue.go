@@ -0,0 +1,62 @@
+package goroutine
+
+import (
+	"container/heap"
+	"math"
+	"time"
+)
+
+// priorityItem is a task waiting in the priority queue
+type priorityItem[T any] struct {
+	job  *job[T]
+	rank int64  // effective priority, higher runs first
+	seq  uint64 // submission order, breaks ties first-in first-out
+}
+
+// priorityQueue is a max-heap of tasks ordered by rank
+type priorityQueue[T any] []*priorityItem[T]
+
+var _ heap.Interface = (*priorityQueue[any])(nil)
+
+func (q priorityQueue[T]) Len() int { return len(q) }
+
+func (q priorityQueue[T]) Less(i, j int) bool {
+	if q[i].rank != q[j].rank {
+		return q[i].rank > q[j].rank
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q priorityQueue[T]) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *priorityQueue[T]) Push(x any) { *q = append(*q, x.(*priorityItem[T])) }
+
+func (q *priorityQueue[T]) Pop() any {
+	old := *q
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return item
+}
+
+// maxAgedPriority bounds priority*aging so subtracting the enqueue time
+// cannot overflow the rank
+const maxAgedPriority = math.MaxInt64 / 4
+
+// priorityRank computes the heap key of a task. With aging, a task gains one
+// priority level for every aging interval it waits: comparing
+// p1 + (now-t1)/aging with p2 + (now-t2)/aging is the same as comparing
+// p1*aging - t1 with p2*aging - t2, which does not change over time, so the
+// heap never needs to be re-sorted. Priorities are clamped to
+// ±maxAgedPriority/aging; beyond that they rank as equal.
+func priorityRank(priority int, enqueued time.Time, aging time.Duration) int64 {
+	if aging <= 0 {
+		return int64(priority)
+	}
+
+	limit := maxAgedPriority / int64(aging)
+	level := min(max(int64(priority), -limit), limit)
+
+	return level*int64(aging) - enqueued.UnixNano()
+}
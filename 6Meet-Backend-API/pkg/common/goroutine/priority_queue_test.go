@@ -0,0 +1,127 @@
+package goroutine
+
+import (
+	"container/heap"
+	"context"
+	"math"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPriorityRank(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		high, low int64
+	}{
+		{
+			name: "higher priority first",
+			high: priorityRank(2, now, time.Second),
+			low:  priorityRank(1, now, time.Second),
+		},
+		{
+			name: "aged task overtakes",
+			high: priorityRank(1, now.Add(-3*time.Second), time.Second),
+			low:  priorityRank(3, now, time.Second),
+		},
+		{
+			name: "huge priorities do not overflow",
+			high: priorityRank(math.MaxInt, now, time.Second),
+			low:  priorityRank(math.MinInt, now, time.Second),
+		},
+		{
+			name: "without aging",
+			high: priorityRank(math.MaxInt, now, 0),
+			low:  priorityRank(math.MaxInt-1, now.Add(-time.Hour), 0),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.high <= tt.low {
+				t.Errorf("rank %d should be above %d", tt.high, tt.low)
+			}
+		})
+	}
+}
+
+func TestPriorityQueueOrder(t *testing.T) {
+	var q priorityQueue[int]
+	for i, rank := range []int64{1, 3, 2, 3} {
+		heap.Push(&q, &priorityItem[int]{job: &job[int]{seq: uint64(i)}, rank: rank, seq: uint64(i)})
+	}
+
+	var got []uint64
+	for q.Len() > 0 {
+		got = append(got, heap.Pop(&q).(*priorityItem[int]).seq)
+	}
+
+	if want := []uint64{1, 3, 2, 0}; !slices.Equal(got, want) {
+		t.Errorf("pop order = %v; want %v", got, want)
+	}
+}
+
+// recorder is a task appending its name to a shared log
+type recorder struct {
+	name string
+	mu   *sync.Mutex
+	log  *[]string
+}
+
+func (r *recorder) Process(ctx context.Context) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	*r.log = append(*r.log, r.name)
+	return r.name, nil
+}
+
+func TestSubmitWithPriorityBeforeStart(t *testing.T) {
+	var (
+		mu  sync.Mutex
+		log []string
+	)
+
+	wp := NewWorkerPool[string](context.Background(), WithMaxWorkers(1), WithAging(0))
+	for i, name := range []string{"low", "high", "mid"} {
+		if err := wp.SubmitWithPriority(&recorder{name: name, mu: &mu, log: &log}, []int{1, 3, 2}[i]); err != nil {
+			t.Fatalf("SubmitWithPriority() error = %v", err)
+		}
+	}
+
+	wp.Start()
+	go wp.CollectResults()
+	if err := wp.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	if want := []string{"high", "mid", "low"}; !slices.Equal(log, want) {
+		t.Errorf("run order = %v; want %v", log, want)
+	}
+}
+
+func TestShutdownDrainsUnstartedPool(t *testing.T) {
+	var (
+		mu  sync.Mutex
+		log []string
+	)
+
+	wp := NewWorkerPool[string](context.Background(), WithMaxWorkers(2))
+	if err := wp.SubmitWithPriority(&recorder{name: "queued", mu: &mu, log: &log}, 0); err != nil {
+		t.Fatalf("SubmitWithPriority() error = %v", err)
+	}
+
+	go wp.CollectResults()
+	if err := wp.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	if !slices.Equal(log, []string{"queued"}) {
+		t.Errorf("ran %v; want the queued task to run on shutdown", log)
+	}
+	if err := wp.SubmitWithPriority(&recorder{name: "late", mu: &mu, log: &log}, 0); err != ErrPoolClosed {
+		t.Errorf("SubmitWithPriority() after Shutdown error = %v; want ErrPoolClosed", err)
+	}
+}
@@ -0,0 +1,65 @@
+package goroutine
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// RetryPolicy controls how failed tasks are retried
+type RetryPolicy struct {
+	MaxAttempts int                             // total attempts per task, values < 1 mean a single attempt
+	Backoff     func(attempt int) time.Duration // delay before the next attempt, nil means no delay
+	Retryable   func(err error) bool            // reports whether an error is worth retrying, nil retries every error
+}
+
+// ExponentialBackoff returns a backoff doubling from base up to max
+func ExponentialBackoff(base, max time.Duration) func(attempt int) time.Duration {
+	return func(attempt int) time.Duration {
+		delay := base
+		for i := 1; i < attempt && delay < max; i++ {
+			delay *= 2
+		}
+		return min(delay, max)
+	}
+}
+
+// attempts returns the number of attempts allowed per task
+func (p RetryPolicy) attempts() int {
+	return max(p.MaxAttempts, 1)
+}
+
+// shouldRetry reports whether an error may be retried. Panics and errors
+// caused by the pool context being cancelled are never retried.
+func (p RetryPolicy) shouldRetry(ctx context.Context, err error) bool {
+	var panicErr *PanicError
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) || errors.As(err, &panicErr) {
+		return false
+	}
+	if p.Retryable == nil {
+		return true
+	}
+	return p.Retryable(err)
+}
+
+// wait sleeps for the backoff of the given attempt or until ctx is done
+func (p RetryPolicy) wait(ctx context.Context, attempt int) error {
+	if p.Backoff == nil {
+		return ctx.Err()
+	}
+
+	delay := p.Backoff(attempt)
+	if delay <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
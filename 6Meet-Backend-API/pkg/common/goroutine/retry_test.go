@@ -0,0 +1,139 @@
+package goroutine
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// taskFunc adapts a function to the Task interface
+type taskFunc[T any] func(ctx context.Context) (T, error)
+
+func (f taskFunc[T]) Process(ctx context.Context) (T, error) {
+	return f(ctx)
+}
+
+// runOne runs a single task on a fresh pool and returns its outcome
+func runOne[T any](t *testing.T, task Task[T], opts ...WorkerPoolOption) (T, error) {
+	t.Helper()
+
+	wp := NewWorkerPool[T](context.Background(), append([]WorkerPoolOption{WithMaxWorkers(1)}, opts...)...)
+	wp.Start()
+	defer wp.Stop()
+
+	future, err := wp.SubmitFuture(task)
+	if err != nil {
+		t.Fatalf("SubmitFuture() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return future.Await(ctx)
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(100*time.Millisecond, time.Second)
+
+	want := []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+		time.Second,
+		time.Second,
+	}
+	for i, w := range want {
+		if got := backoff(i + 1); got != w {
+			t.Errorf("backoff(%d) = %v; want %v", i+1, got, w)
+		}
+	}
+}
+
+func TestRetryPolicyShouldRetry(t *testing.T) {
+	errPermanent := errors.New("permanent")
+	policy := RetryPolicy{Retryable: func(err error) bool { return !errors.Is(err, errPermanent) }}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  error
+		want bool
+	}{
+		{name: "retryable", ctx: context.Background(), err: errors.New("transient"), want: true},
+		{name: "not retryable", ctx: context.Background(), err: errPermanent, want: false},
+		{name: "panic", ctx: context.Background(), err: &PanicError{Value: "boom"}, want: false},
+		{name: "task cancelled", ctx: context.Background(), err: context.Canceled, want: false},
+		{name: "pool cancelled", ctx: cancelled, err: errors.New("transient"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.shouldRetry(tt.ctx, tt.err); got != tt.want {
+				t.Errorf("shouldRetry(%v) = %v; want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWorkerPoolRetriesUntilSuccess(t *testing.T) {
+	var calls atomic.Int32
+	task := taskFunc[int](func(ctx context.Context) (int, error) {
+		if calls.Add(1) < 3 {
+			return 0, errors.New("transient")
+		}
+		return 42, nil
+	})
+
+	got, err := runOne[int](t, task, WithMaxAttempts(3))
+	if err != nil || got != 42 {
+		t.Fatalf("task = %d, %v; want 42, nil", got, err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("task ran %d times; want 3", calls.Load())
+	}
+}
+
+func TestWorkerPoolStopsOnNonRetryableError(t *testing.T) {
+	errPermanent := errors.New("permanent")
+
+	var calls atomic.Int32
+	task := taskFunc[int](func(ctx context.Context) (int, error) {
+		calls.Add(1)
+		return 0, errPermanent
+	})
+
+	_, err := runOne[int](t, task,
+		WithMaxAttempts(5),
+		WithRetryable(func(err error) bool { return !errors.Is(err, errPermanent) }),
+	)
+
+	var taskErr *TaskError
+	if !errors.As(err, &taskErr) || taskErr.Attempts != 1 || !errors.Is(err, errPermanent) {
+		t.Fatalf("error = %v; want a TaskError after 1 attempt wrapping errPermanent", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("task ran %d times; want 1", calls.Load())
+	}
+}
+
+func TestWorkerPoolTimeoutPerAttempt(t *testing.T) {
+	var calls atomic.Int32
+	task := taskFunc[int](func(ctx context.Context) (int, error) {
+		calls.Add(1)
+		<-ctx.Done()
+		return 0, ctx.Err()
+	})
+
+	_, err := runOne[int](t, task, WithTimeout(10*time.Millisecond), WithMaxAttempts(2))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("error = %v; want context.DeadlineExceeded", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("task ran %d times; want 2, one per timed out attempt", calls.Load())
+	}
+}
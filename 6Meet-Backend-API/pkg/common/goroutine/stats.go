@@ -0,0 +1,111 @@
+package goroutine
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// latencyWindow is the number of recent task latencies kept for percentiles
+const latencyWindow = 1024
+
+// Stats is a point-in-time snapshot of a worker pool
+type Stats struct {
+	State      string        `json:"state"`          // lifecycle state, see PoolState
+	Workers    int           `json:"workers"`        // running workers
+	Active     int           `json:"active"`         // workers currently processing a task
+	Queued     int           `json:"queued"`         // tasks waiting for a worker
+	Completed  int64         `json:"completed"`      // tasks finished successfully
+	Failed     int64         `json:"failed"`         // tasks finished with an error
+	AvgLatency time.Duration `json:"avg_latency_ns"` // mean processing time of all finished tasks
+	P50Latency time.Duration `json:"p50_latency_ns"` // percentiles over the most recent tasks
+	P95Latency time.Duration `json:"p95_latency_ns"`
+	P99Latency time.Duration `json:"p99_latency_ns"`
+}
+
+// StatsProvider is implemented by pools that report live statistics
+type StatsProvider interface {
+	Stats() Stats
+}
+
+// latencyRecorder tracks task latencies in a fixed-size ring buffer
+type latencyRecorder struct {
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int
+	count   int64
+	total   time.Duration
+}
+
+// record adds the latency of a finished task
+func (r *latencyRecorder) record(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.samples) < latencyWindow {
+		r.samples = append(r.samples, d)
+	} else {
+		r.samples[r.next] = d
+		r.next = (r.next + 1) % latencyWindow
+	}
+	r.count++
+	r.total += d
+}
+
+// fill writes the average and percentile latencies into stats
+func (r *latencyRecorder) fill(stats *Stats) {
+	r.mu.Lock()
+	sorted := slices.Clone(r.samples)
+	count, total := r.count, r.total
+	r.mu.Unlock()
+
+	if count == 0 {
+		return
+	}
+
+	slices.Sort(sorted)
+	stats.AvgLatency = total / time.Duration(count)
+	stats.P50Latency = percentile(sorted, 0.50)
+	stats.P95Latency = percentile(sorted, 0.95)
+	stats.P99Latency = percentile(sorted, 0.99)
+}
+
+// percentile returns the nearest-rank percentile of sorted samples
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	idx := int(float64(len(sorted))*p+0.5) - 1
+	return sorted[min(max(idx, 0), len(sorted)-1)]
+}
+
+// registry holds the named pools exposed through AllStats
+var registry = struct {
+	sync.RWMutex
+	pools map[string]StatsProvider
+}{pools: make(map[string]StatsProvider)}
+
+// register exposes a pool under a name
+func register(name string, p StatsProvider) {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.pools[name] = p
+}
+
+// unregister removes a named pool
+func unregister(name string, p StatsProvider) {
+	registry.Lock()
+	defer registry.Unlock()
+	if registry.pools[name] == p {
+		delete(registry.pools, name)
+	}
+}
+
+// AllStats returns the statistics of every running named pool (see WithName)
+func AllStats() map[string]Stats {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	stats := make(map[string]Stats, len(registry.pools))
+	for name, p := range registry.pools {
+		stats[name] = p.Stats()
+	}
+	return stats
+}
@@ -1,10 +1,13 @@
 package goroutine
 
 import (
+	"container/heap"
 	"context"
 	"fmt"
 	"runtime"
+	"runtime/debug"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -13,19 +16,65 @@ type Task[T any] interface {
 	Process(ctx context.Context) (T, error)
 }
 
+// Identifier is implemented by tasks that can name themselves in errors
+type Identifier interface {
+	TaskID() string
+}
+
+// taskID returns the identity of a task used in errors
+func taskID(task any) string {
+	if t, ok := task.(Identifier); ok {
+		return t.TaskID()
+	}
+	return fmt.Sprintf("%T", task)
+}
+
 // WorkerPool manages a pool of workers for concurrent task processing
 type WorkerPool[T any] struct {
+	name        string             // name under which the pool reports its stats, optional
 	maxWorkers  int                // maximum number of concurrent workers
-	taskQueue   chan Task[T]       // channel for tasks
+	taskQueue   chan *job[T]       // channel for tasks
 	results     chan T             // channel for results
 	errors      chan error         // channel for errors
-	timeout     time.Duration      // timeout for task processing
+	outcomes    chan Outcome[T]    // channel for outcome envelopes
+	envelopes   bool               // emit outcomes instead of results and errors
+	ordered     bool               // emit in submission order
+	finished    chan Outcome[T]    // tasks finished by workers, read by the emitter
+	seq         atomic.Uint64      // submission counter of channel tasks
+	emitWg      sync.WaitGroup     // wait group for the emitter
+	timeout     time.Duration      // timeout for a single task attempt
+	retry       RetryPolicy        // retry policy for failed tasks
 	stopOnError bool               // whether to stop processing on first error
 	wg          sync.WaitGroup     // wait group for workers
 	ctx         context.Context    // context for pool
 	cancel      context.CancelFunc // cancel pool context
+
+	aging      time.Duration    // waiting time that raises a queued task by one priority level
+	pqMu       sync.Mutex       // guards pq, pqSeq and pqClosed
+	pq         priorityQueue[T] // tasks submitted with a priority
+	pqSeq      uint64           // submission counter for FIFO tie-breaking
+	pqClosed   bool             // no more prioritized submissions are accepted
+	pqSignal   chan struct{}    // wakes the dispatcher
+	dispatchWg sync.WaitGroup   // wait group for the dispatcher
+
+	mu         sync.Mutex     // guards maxWorkers, started, state, submitting, retiring and wake
+	started    bool           // workers have been started
+	state      PoolState      // lifecycle state
+	submitting sync.WaitGroup // Submit calls accepted while running
+	stopped    chan struct{}  // closed once Shutdown has completed
+	retiring   int            // workers asked to exit after a shrink that have not exited yet
+	wake       chan struct{}  // closed to make idle workers check for retirement
+
+	live      atomic.Int64    // running workers
+	active    atomic.Int64    // workers processing a task
+	waiting   atomic.Int64    // Submit calls waiting for a worker
+	completed atomic.Int64    // tasks finished successfully
+	failed    atomic.Int64    // tasks finished with an error
+	latency   latencyRecorder // task processing times
 }
 
+var _ StatsProvider = (*WorkerPool[any])(nil)
+
 // WorkerPoolOption represents configuration options for WorkerPool
 type WorkerPoolOption func(*WorkerPoolConfig)
 
@@ -33,7 +82,12 @@ type WorkerPoolOption func(*WorkerPoolConfig)
 type WorkerPoolConfig struct {
 	MaxWorkers  int
 	Timeout     time.Duration
+	Retry       RetryPolicy
 	StopOnError bool
+	Aging       time.Duration
+	Name        string
+	Outcomes    bool
+	Ordered     bool
 }
 
 // WithMaxWorkers sets the maximum number of concurrent workers
@@ -43,13 +97,42 @@ func WithMaxWorkers(n int) WorkerPoolOption {
 	}
 }
 
-// WithTimeout sets the timeout for task processing
+// WithTimeout sets the timeout of each task attempt. The deadline is derived
+// from the pool context, so it never outlives the pool. Zero disables it.
 func WithTimeout(d time.Duration) WorkerPoolOption {
 	return func(c *WorkerPoolConfig) {
 		c.Timeout = d
 	}
 }
 
+// WithRetryPolicy sets the retry policy for failed tasks
+func WithRetryPolicy(policy RetryPolicy) WorkerPoolOption {
+	return func(c *WorkerPoolConfig) {
+		c.Retry = policy
+	}
+}
+
+// WithMaxAttempts sets the number of attempts per task
+func WithMaxAttempts(n int) WorkerPoolOption {
+	return func(c *WorkerPoolConfig) {
+		c.Retry.MaxAttempts = n
+	}
+}
+
+// WithBackoff sets the delay between attempts of a task
+func WithBackoff(backoff func(attempt int) time.Duration) WorkerPoolOption {
+	return func(c *WorkerPoolConfig) {
+		c.Retry.Backoff = backoff
+	}
+}
+
+// WithRetryable sets the predicate deciding which errors are retried
+func WithRetryable(retryable func(err error) bool) WorkerPoolOption {
+	return func(c *WorkerPoolConfig) {
+		c.Retry.Retryable = retryable
+	}
+}
+
 // WithStopOnError sets whether to stop processing on first error
 func WithStopOnError(stop bool) WorkerPoolOption {
 	return func(c *WorkerPoolConfig) {
@@ -57,29 +140,72 @@ func WithStopOnError(stop bool) WorkerPoolOption {
 	}
 }
 
+// WithAging sets how long a prioritized task must wait to gain one priority
+// level, which keeps low-priority tasks from starving. Zero disables aging.
+func WithAging(d time.Duration) WorkerPoolOption {
+	return func(c *WorkerPoolConfig) {
+		c.Aging = d
+	}
+}
+
+// WithName names the pool so its statistics are reported by AllStats while it runs
+func WithName(name string) WorkerPoolOption {
+	return func(c *WorkerPoolConfig) {
+		c.Name = name
+	}
+}
+
+// WithOutcomes sets whether tasks are reported as Outcome envelopes on the
+// Outcomes channel instead of on the result and error channels
+func WithOutcomes(enabled bool) WorkerPoolOption {
+	return func(c *WorkerPoolConfig) {
+		c.Outcomes = enabled
+	}
+}
+
+// WithOrderedResults sets whether results are emitted in submission order.
+// A finished task is held back until every task submitted before it has
+// finished, so one slow task delays the ones behind it.
+func WithOrderedResults(ordered bool) WorkerPoolOption {
+	return func(c *WorkerPoolConfig) {
+		c.Ordered = ordered
+	}
+}
+
 // NewWorkerPool creates a new WorkerPool with the given options
 func NewWorkerPool[T any](ctx context.Context, opts ...WorkerPoolOption) *WorkerPool[T] {
 	config := &WorkerPoolConfig{
 		MaxWorkers:  10,
 		Timeout:     5 * time.Minute,
 		StopOnError: false,
+		Aging:       10 * time.Second,
 	}
 
 	for _, opt := range opts {
 		opt(config)
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, config.Timeout)
+	ctx, cancel := context.WithCancel(ctx)
 
 	wp := &WorkerPool[T]{
+		name:        config.Name,
 		maxWorkers:  config.MaxWorkers,
-		taskQueue:   make(chan Task[T]),
+		taskQueue:   make(chan *job[T]),
 		results:     make(chan T, config.MaxWorkers),
 		errors:      make(chan error, config.MaxWorkers),
+		outcomes:    make(chan Outcome[T], config.MaxWorkers),
+		envelopes:   config.Outcomes,
+		ordered:     config.Ordered,
+		finished:    make(chan Outcome[T], config.MaxWorkers),
 		timeout:     config.Timeout,
+		retry:       config.Retry,
 		stopOnError: config.StopOnError,
 		ctx:         ctx,
 		cancel:      cancel,
+		aging:       config.Aging,
+		pqSignal:    make(chan struct{}, 1),
+		wake:        make(chan struct{}),
+		stopped:     make(chan struct{}),
 	}
 
 	return wp
@@ -113,93 +239,455 @@ func NewCPUExecutor[T any](ctx context.Context, opts ...WorkerPoolOption) *Worke
 	return NewWorkerPool[T](ctx, opts...)
 }
 
-// Submit adds a task to the worker pool
+// Submit adds a task to the worker pool. Its result is reported on the
+// result and error channels, or on the Outcomes channel with WithOutcomes.
 func (wp *WorkerPool[T]) Submit(task Task[T]) error {
+	return wp.submit(&job[T]{task: task})
+}
+
+// SubmitFuture adds a task to the worker pool and returns a handle to its
+// result. The result is only reported through the future, never on the
+// pool channels.
+func (wp *WorkerPool[T]) SubmitFuture(task Task[T]) (*Future[T], error) {
+	future := newFuture[T](taskID(task))
+	if err := wp.submit(&job[T]{task: task, future: future}); err != nil {
+		return nil, err
+	}
+	return future, nil
+}
+
+// submit hands a job to a worker. The call is registered while the pool
+// is running, so Shutdown waits for it before closing the task queue. Only
+// accepted jobs take a sequence number, so a rejected job never holds back
+// ordered outcomes.
+func (wp *WorkerPool[T]) submit(j *job[T]) error {
+	wp.mu.Lock()
+	if wp.state != PoolRunning {
+		wp.mu.Unlock()
+		return ErrPoolClosed
+	}
+	if j.future == nil {
+		j.seq = wp.seq.Add(1)
+	}
+	wp.submitting.Add(1)
+	wp.mu.Unlock()
+	defer wp.submitting.Done()
+
+	wp.waiting.Add(1)
+	defer wp.waiting.Add(-1)
+
 	// Blocking send: wait until a worker receives the task or pool context is done
 	select {
-	case wp.taskQueue <- task:
+	case wp.taskQueue <- j:
 		return nil
 	case <-wp.ctx.Done():
-		return fmt.Errorf("worker pool is closed or context cancelled")
+		return ErrPoolClosed
+	}
+}
+
+// SubmitWithPriority queues a task that is handed to workers before tasks
+// of lower priority. It does not block; waiting tasks age (see WithAging)
+// so low priorities still make progress. Plain Submit calls compete with
+// the highest queued task for the next free worker. Tasks submitted before
+// Start wait in the queue until the pool is started or shut down.
+func (wp *WorkerPool[T]) SubmitWithPriority(task Task[T], priority int) error {
+	if wp.State() != PoolRunning || wp.ctx.Err() != nil {
+		return ErrPoolClosed
+	}
+
+	wp.pqMu.Lock()
+	if wp.pqClosed {
+		wp.pqMu.Unlock()
+		return ErrPoolClosed
+	}
+	wp.pqSeq++
+	heap.Push(&wp.pq, &priorityItem[T]{
+		job:  &job[T]{task: task, seq: wp.seq.Add(1)},
+		rank: priorityRank(priority, time.Now(), wp.aging),
+		seq:  wp.pqSeq,
+	})
+	wp.pqMu.Unlock()
+
+	wp.wakeDispatcher()
+	return nil
+}
+
+// wakeDispatcher signals the dispatcher without blocking
+func (wp *WorkerPool[T]) wakeDispatcher() {
+	select {
+	case wp.pqSignal <- struct{}{}:
+	default:
 	}
 }
 
 // Start begins processing tasks
 func (wp *WorkerPool[T]) Start() {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	if wp.state != PoolRunning {
+		return
+	}
+	wp.start()
+}
+
+// start launches the workers, the dispatcher and the emitter once. The
+// caller must hold wp.mu.
+func (wp *WorkerPool[T]) start() {
+	if wp.started {
+		return
+	}
+	wp.started = true
+
 	for i := 0; i < wp.maxWorkers; i++ {
 		wp.wg.Add(1)
 		go wp.worker()
 	}
+
+	wp.dispatchWg.Add(1)
+	go wp.dispatcher()
+
+	wp.emitWg.Add(1)
+	go wp.emitter()
+
+	if wp.name != "" {
+		register(wp.name, wp)
+	}
+}
+
+// Resize changes the number of workers at runtime. Growing first cancels
+// pending retirements, then starts workers immediately; shrinking retires
+// workers as soon as they are idle, so in-flight tasks are never
+// interrupted and the pool never runs more than n workers once the busy
+// ones have finished.
+func (wp *WorkerPool[T]) Resize(n int) error {
+	if n < 1 {
+		return fmt.Errorf("worker pool size must be at least 1, got %d", n)
+	}
+
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	if wp.state != PoolRunning || wp.ctx.Err() != nil {
+		return ErrPoolClosed
+	}
+
+	diff := n - wp.maxWorkers
+	wp.maxWorkers = n
+	if !wp.started {
+		return nil
+	}
+
+	if diff < 0 {
+		wp.retiring -= diff
+		close(wp.wake)
+		wp.wake = make(chan struct{})
+		return nil
+	}
+
+	kept := min(diff, wp.retiring)
+	wp.retiring -= kept
+	for i := kept; i < diff; i++ {
+		wp.wg.Add(1)
+		go wp.worker()
+	}
+
+	return nil
+}
+
+// retire reports whether the calling worker should exit after a shrink.
+// Otherwise it returns the channel closed on the next shrink.
+func (wp *WorkerPool[T]) retire() (bool, <-chan struct{}) {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	if wp.retiring > 0 {
+		wp.retiring--
+		return true, nil
+	}
+	return false, wp.wake
+}
+
+// Size returns the configured number of workers
+func (wp *WorkerPool[T]) Size() int {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+	return wp.maxWorkers
+}
+
+// State returns the lifecycle state of the pool
+func (wp *WorkerPool[T]) State() PoolState {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+	return wp.state
+}
+
+// Stats returns a snapshot of the pool activity
+func (wp *WorkerPool[T]) Stats() Stats {
+	wp.pqMu.Lock()
+	queued := wp.pq.Len()
+	wp.pqMu.Unlock()
+
+	stats := Stats{
+		State:     wp.State().String(),
+		Workers:   int(wp.live.Load()),
+		Active:    int(wp.active.Load()),
+		Queued:    queued + int(wp.waiting.Load()),
+		Completed: wp.completed.Load(),
+		Failed:    wp.failed.Load(),
+	}
+	wp.latency.fill(&stats)
+
+	return stats
+}
+
+// dispatcher hands prioritized tasks to workers, highest rank first. It
+// exits once the queue is closed and drained, or the pool context is done.
+func (wp *WorkerPool[T]) dispatcher() {
+	defer wp.dispatchWg.Done()
+
+	for {
+		wp.pqMu.Lock()
+		if wp.pq.Len() == 0 {
+			closed := wp.pqClosed
+			wp.pqMu.Unlock()
+			if closed {
+				return
+			}
+
+			select {
+			case <-wp.pqSignal:
+				continue
+			case <-wp.ctx.Done():
+				return
+			}
+		}
+		item := heap.Pop(&wp.pq).(*priorityItem[T])
+		wp.pqMu.Unlock()
+
+		select {
+		case wp.taskQueue <- item.job:
+		case <-wp.ctx.Done():
+			return
+		}
+	}
 }
 
 // worker processes tasks
 func (wp *WorkerPool[T]) worker() {
 	defer wp.wg.Done()
 
+	wp.live.Add(1)
+	defer wp.live.Add(-1)
+
 	for {
+		retire, wake := wp.retire()
+		if retire {
+			return
+		}
+
 		select {
 		case <-wp.ctx.Done():
 			return
-		case task, ok := <-wp.taskQueue:
+		case <-wake:
+		case j, ok := <-wp.taskQueue:
 			if !ok {
 				return
 			}
 
-			result, err := task.Process(wp.ctx)
+			wp.active.Add(1)
+			start := time.Now()
+			result, err := wp.process(j.task)
+			wp.latency.record(time.Since(start))
+			wp.active.Add(-1)
+
 			if err != nil {
-				select {
-				case wp.errors <- err:
-				case <-wp.ctx.Done():
-					return
-				}
-
-				if wp.stopOnError {
-					wp.cancel()
-					return
-				}
-				continue
+				wp.failed.Add(1)
+			} else {
+				wp.completed.Add(1)
 			}
 
-			select {
-			case wp.results <- result:
-			case <-wp.ctx.Done():
+			if j.future != nil {
+				j.future.resolve(result, err)
+			} else {
+				send(wp.ctx, wp.finished, Outcome[T]{
+					Seq:    j.seq,
+					TaskID: taskID(j.task),
+					Value:  result,
+					Err:    err,
+				})
+			}
+
+			if err != nil && wp.stopOnError {
+				wp.cancel()
 				return
 			}
 		}
 	}
 }
 
-// Results returns channels for results and errors
+// process runs a task, retrying it according to the retry policy. The final
+// error is wrapped in a TaskError carrying the task identity and attempts.
+func (wp *WorkerPool[T]) process(task Task[T]) (T, error) {
+	var (
+		result   T
+		err      error
+		attempts int
+	)
+
+	for attempts < wp.retry.attempts() {
+		attempts++
+
+		result, err = wp.attempt(task)
+		if err == nil {
+			return result, nil
+		}
+
+		if attempts >= wp.retry.attempts() || !wp.retry.shouldRetry(wp.ctx, err) {
+			break
+		}
+		if wp.retry.wait(wp.ctx, attempts) != nil {
+			break
+		}
+	}
+
+	return result, &TaskError{
+		TaskID:   taskID(task),
+		Attempts: attempts,
+		Err:      err,
+	}
+}
+
+// attempt runs a single attempt of a task under the per-task timeout. A panic
+// inside the task is recovered and returned as a PanicError so the worker
+// keeps running.
+func (wp *WorkerPool[T]) attempt(task Task[T]) (result T, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{
+				TaskID: taskID(task),
+				Value:  r,
+				Stack:  debug.Stack(),
+			}
+		}
+	}()
+
+	ctx := wp.ctx
+	if wp.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(wp.ctx, wp.timeout)
+		defer cancel()
+	}
+
+	return task.Process(ctx)
+}
+
+// Results returns channels for results and errors. They stay empty when
+// the pool reports outcomes, see WithOutcomes.
 func (wp *WorkerPool[T]) Results() (<-chan T, <-chan error) {
 	return wp.results, wp.errors
 }
 
+// Outcomes returns the channel of outcome envelopes. It is only fed when the
+// pool was created WithOutcomes and is closed by Shutdown.
+func (wp *WorkerPool[T]) Outcomes() <-chan Outcome[T] {
+	return wp.outcomes
+}
+
 // Wait waits for worker goroutines to finish processing current tasks.
 func (wp *WorkerPool[T]) Wait() {
 	wp.wg.Wait()
 }
 
-// Shutdown stops accepting new submits, waits for in-flight Submit calls,
-// closes the task queue to signal workers, waits for workers to finish and
-// then closes result and error channels.
-func (wp *WorkerPool[T]) Shutdown() {
+// Shutdown stops accepting tasks and drains the pool: Submit calls already
+// accepted are handed to workers, queued prioritized tasks are dispatched
+// and every task finishes before the result channels are closed. A pool
+// that was never started is started to drain them. If ctx is done first,
+// in-flight tasks are cancelled and ctx.Err() is returned once the workers
+// have exited. Shutdown is safe to call more than once and from several
+// goroutines; later calls wait for the first one to complete.
+func (wp *WorkerPool[T]) Shutdown(ctx context.Context) error {
+	wp.mu.Lock()
+	if wp.state != PoolRunning {
+		wp.mu.Unlock()
+		select {
+		case <-wp.stopped:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	wp.state = PoolDraining
+	wp.start()
+	wp.mu.Unlock()
+
+	if wp.name != "" {
+		unregister(wp.name, wp)
+	}
+
+	drained := make(chan struct{})
+	go func() {
+		defer close(drained)
+		wp.drain()
+	}()
+
+	var err error
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		err = ctx.Err()
+		wp.cancel()
+		<-drained
+	}
+
+	wp.cancel()
+
+	wp.mu.Lock()
+	wp.state = PoolStopped
+	wp.mu.Unlock()
+	close(wp.stopped)
+
+	return err
+}
+
+// ShutdownNow stops accepting tasks, cancels the tasks in flight and waits
+// for the workers to exit
+func (wp *WorkerPool[T]) ShutdownNow() {
+	wp.cancel()
+	_ = wp.Shutdown(context.Background())
+}
+
+// drain waits for accepted Submit calls, dispatches the queued prioritized
+// tasks, closes the task queue, waits for workers to finish and then
+// closes result and error channels
+func (wp *WorkerPool[T]) drain() {
+	// No new Submit call can register once the pool is draining
+	wp.submitting.Wait()
+
+	// Stop accepting prioritized tasks and wait until the queued ones are dispatched
+	wp.pqMu.Lock()
+	wp.pqClosed = true
+	wp.pqMu.Unlock()
+	wp.wakeDispatcher()
+	wp.dispatchWg.Wait()
+
 	// Close task queue to signal no more tasks.
 	close(wp.taskQueue)
 
-	// Wait for workers to finish
+	// Wait for workers to finish, then for their results to be emitted
 	wp.wg.Wait()
+	close(wp.finished)
+	wp.emitWg.Wait()
 
 	// Close result channels
 	close(wp.results)
 	close(wp.errors)
-
-	// Cancel pool context
-	wp.cancel()
+	close(wp.outcomes)
 }
 
-// Stop is an alias for Shutdown for backward-compatibility
+// Stop is an alias for Shutdown without a deadline for backward-compatibility
 func (wp *WorkerPool[T]) Stop() {
-	wp.Shutdown()
+	_ = wp.Shutdown(context.Background())
 }
 
 // CollectResults collects all results and errors until the worker pool is done
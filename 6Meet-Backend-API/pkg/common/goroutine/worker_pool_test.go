@@ -0,0 +1,324 @@
+package goroutine
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// eventually fails the test unless cond holds within a second
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// blockingTask waits for the gate to close or its context to be done
+func blockingTask(gate <-chan struct{}) Task[int] {
+	return taskFunc[int](func(ctx context.Context) (int, error) {
+		select {
+		case <-gate:
+			return 1, nil
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	})
+}
+
+// fillPool submits one blocking task per worker and waits until all run
+func fillPool(t *testing.T, wp *WorkerPool[int], gate <-chan struct{}, n int) []*Future[int] {
+	t.Helper()
+
+	futures := make([]*Future[int], n)
+	for i := range futures {
+		future, err := wp.SubmitFuture(blockingTask(gate))
+		if err != nil {
+			t.Fatalf("SubmitFuture() error = %v", err)
+		}
+		futures[i] = future
+	}
+	eventually(t, "every worker to be busy", func() bool { return wp.Stats().Active == n })
+
+	return futures
+}
+
+func TestResizeShrinkRetiresIdleWorkers(t *testing.T) {
+	wp := NewWorkerPool[int](context.Background(), WithMaxWorkers(4))
+	wp.Start()
+	defer wp.Stop()
+
+	gate := make(chan struct{})
+	futures := fillPool(t, wp, gate, 4)
+
+	if err := wp.Resize(2); err != nil {
+		t.Fatalf("Resize() error = %v", err)
+	}
+	if got := wp.Stats().Workers; got != 4 {
+		t.Errorf("workers while busy = %d; want 4, in-flight tasks are not interrupted", got)
+	}
+
+	close(gate)
+	for _, future := range futures {
+		if _, err := future.Await(context.Background()); err != nil {
+			t.Errorf("in-flight task error = %v", err)
+		}
+	}
+	eventually(t, "the pool to shrink to 2 workers", func() bool { return wp.Stats().Workers == 2 })
+	if got := wp.Size(); got != 2 {
+		t.Errorf("Size() = %d; want 2", got)
+	}
+}
+
+func TestResizeGrowCancelsPendingRetirements(t *testing.T) {
+	wp := NewWorkerPool[int](context.Background(), WithMaxWorkers(4))
+	wp.Start()
+	defer wp.Stop()
+
+	gate := make(chan struct{})
+	fillPool(t, wp, gate, 4)
+
+	if err := wp.Resize(1); err != nil {
+		t.Fatalf("Resize(1) error = %v", err)
+	}
+	if err := wp.Resize(4); err != nil {
+		t.Fatalf("Resize(4) error = %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if got := wp.Stats().Workers; got != 4 {
+		t.Errorf("workers after shrink and grow = %d; want 4", got)
+	}
+
+	if err := wp.Resize(6); err != nil {
+		t.Fatalf("Resize(6) error = %v", err)
+	}
+	eventually(t, "the pool to grow to 6 workers", func() bool { return wp.Stats().Workers == 6 })
+
+	close(gate)
+	time.Sleep(10 * time.Millisecond)
+	if got := wp.Stats().Workers; got != 6 {
+		t.Errorf("workers once idle = %d; want 6", got)
+	}
+}
+
+func TestResizeRejectsInvalidSizes(t *testing.T) {
+	wp := NewWorkerPool[int](context.Background())
+	if err := wp.Resize(0); err == nil {
+		t.Error("Resize(0) succeeded")
+	}
+
+	wp.Stop()
+	if err := wp.Resize(2); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("Resize() after Stop error = %v; want ErrPoolClosed", err)
+	}
+}
+
+func TestStatsCountsTasks(t *testing.T) {
+	wp := NewWorkerPool[int](context.Background(), WithMaxWorkers(2))
+	wp.Start()
+
+	ok := taskFunc[int](func(ctx context.Context) (int, error) { return 1, nil })
+	fail := taskFunc[int](func(ctx context.Context) (int, error) { return 0, errors.New("failed") })
+
+	go wp.CollectResults()
+	for _, task := range []Task[int]{ok, ok, fail} {
+		if err := wp.Submit(task); err != nil {
+			t.Fatalf("Submit() error = %v", err)
+		}
+	}
+	wp.Stop()
+
+	stats := wp.Stats()
+	if stats.Completed != 2 || stats.Failed != 1 {
+		t.Errorf("Completed, Failed = %d, %d; want 2, 1", stats.Completed, stats.Failed)
+	}
+	if stats.State != PoolStopped.String() || stats.Workers != 0 {
+		t.Errorf("State, Workers = %s, %d; want stopped, 0", stats.State, stats.Workers)
+	}
+}
+
+func TestSubmitDuringShutdown(t *testing.T) {
+	for round := 0; round < 20; round++ {
+		wp := NewWorkerPool[int](context.Background(), WithMaxWorkers(4))
+		wp.Start()
+		go wp.CollectResults()
+
+		ok := taskFunc[int](func(ctx context.Context) (int, error) { return 1, nil })
+
+		var wg sync.WaitGroup
+		var accepted atomic.Int64
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 50; j++ {
+					err := wp.Submit(ok)
+					if errors.Is(err, ErrPoolClosed) {
+						return
+					}
+					if err != nil {
+						t.Errorf("Submit() error = %v", err)
+						return
+					}
+					accepted.Add(1)
+				}
+			}()
+		}
+
+		if err := wp.Shutdown(context.Background()); err != nil {
+			t.Fatalf("Shutdown() error = %v", err)
+		}
+		wg.Wait()
+
+		if stats := wp.Stats(); stats.Completed != accepted.Load() {
+			t.Fatalf("completed %d tasks; want every one of the %d accepted", stats.Completed, accepted.Load())
+		}
+	}
+}
+
+func TestRejectedSubmitKeepsSequence(t *testing.T) {
+	wp := NewWorkerPool[int](context.Background(), WithMaxWorkers(1), WithOutcomes(true), WithOrderedResults(true))
+	wp.Start()
+
+	var seqs []uint64
+	collected := make(chan struct{})
+	go func() {
+		defer close(collected)
+		for outcome := range wp.Outcomes() {
+			seqs = append(seqs, outcome.Seq)
+		}
+	}()
+
+	ok := taskFunc[int](func(ctx context.Context) (int, error) { return 1, nil })
+	for i := 0; i < 2; i++ {
+		if err := wp.Submit(ok); err != nil {
+			t.Fatalf("Submit() error = %v", err)
+		}
+	}
+	if err := wp.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	<-collected
+
+	if err := wp.Submit(ok); !errors.Is(err, ErrPoolClosed) {
+		t.Fatalf("Submit() after Shutdown error = %v; want ErrPoolClosed", err)
+	}
+	if got := wp.seq.Load(); got != 2 {
+		t.Errorf("sequence = %d after a rejected submit; want 2, the accepted tasks only", got)
+	}
+	if len(seqs) != 2 || seqs[0] != 1 || seqs[1] != 2 {
+		t.Errorf("outcome sequences = %v; want [1 2]", seqs)
+	}
+}
+
+func TestShutdownTwice(t *testing.T) {
+	wp := NewWorkerPool[int](context.Background(), WithMaxWorkers(2))
+	wp.Start()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := wp.Shutdown(context.Background()); err != nil {
+				t.Errorf("Shutdown() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	wp.Stop()
+	wp.ShutdownNow()
+
+	if got := wp.State(); got != PoolStopped {
+		t.Errorf("State() = %s; want stopped", got)
+	}
+	if err := wp.Submit(taskFunc[int](func(ctx context.Context) (int, error) { return 0, nil })); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("Submit() after Shutdown error = %v; want ErrPoolClosed", err)
+	}
+}
+
+func TestShutdownDrainsAcceptedTasks(t *testing.T) {
+	wp := NewWorkerPool[int](context.Background(), WithMaxWorkers(2), WithOutcomes(true))
+	wp.Start()
+
+	gate := make(chan struct{})
+	futures := fillPool(t, wp, gate, 2)
+
+	done := make(chan error)
+	go func() { done <- wp.Shutdown(context.Background()) }()
+
+	eventually(t, "the pool to drain", func() bool { return wp.State() == PoolDraining })
+	select {
+	case err := <-done:
+		t.Fatalf("Shutdown() returned %v before the in-flight tasks finished", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	close(gate)
+	if err := <-done; err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	for _, future := range futures {
+		if value, err := future.Await(context.Background()); err != nil || value != 1 {
+			t.Errorf("drained task = %d, %v; want 1, nil", value, err)
+		}
+	}
+	if _, open := <-wp.Outcomes(); open {
+		t.Error("Outcomes() still open after Shutdown")
+	}
+}
+
+func TestShutdownDeadlineCancelsTasks(t *testing.T) {
+	wp := NewWorkerPool[int](context.Background(), WithMaxWorkers(2))
+	wp.Start()
+
+	futures := fillPool(t, wp, make(chan struct{}), 2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := wp.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown() error = %v; want context.DeadlineExceeded", err)
+	}
+	for _, future := range futures {
+		if _, err := future.Await(context.Background()); !errors.Is(err, context.Canceled) {
+			t.Errorf("in-flight task error = %v; want context.Canceled", err)
+		}
+	}
+	if got := wp.State(); got != PoolStopped {
+		t.Errorf("State() = %s; want stopped", got)
+	}
+}
+
+func TestShutdownNowCancelsInFlightTasks(t *testing.T) {
+	wp := NewWorkerPool[int](context.Background(), WithMaxWorkers(3))
+	wp.Start()
+
+	futures := fillPool(t, wp, make(chan struct{}), 3)
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		wp.ShutdownNow()
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("ShutdownNow() did not return")
+	}
+	for _, future := range futures {
+		if _, err := future.Await(context.Background()); !errors.Is(err, context.Canceled) {
+			t.Errorf("in-flight task error = %v; want context.Canceled", err)
+		}
+	}
+}
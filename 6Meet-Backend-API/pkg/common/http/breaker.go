@@ -0,0 +1,284 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned when a host's circuit breaker rejects a request
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// BreakerState is the state of a host's circuit breaker
+type BreakerState int
+
+const (
+	// BreakerClosed lets every request through
+	BreakerClosed BreakerState = iota
+	// BreakerOpen rejects every request until the cool-down has passed
+	BreakerOpen
+	// BreakerHalfOpen lets a few probe requests through to test the host
+	BreakerHalfOpen
+)
+
+// String returns the name of the state
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// BreakerConfig configures the per-host circuit breakers
+type BreakerConfig struct {
+	FailureRatio   float64       // failed share of requests that opens the breaker, 0 disables breaking
+	MinRequests    int           // requests in the window before the ratio is considered
+	Window         time.Duration // period over which requests are counted
+	Cooldown       time.Duration // time the breaker stays open before probing
+	HalfOpenProbes int           // successful probes needed to close the breaker again
+
+	// OnStateChange is called after a host's breaker changes state
+	OnStateChange func(host string, from, to BreakerState)
+}
+
+// circuitBreaker tracks the health of a single host
+type circuitBreaker struct {
+	mu        sync.Mutex
+	state     BreakerState
+	start     time.Time     // start of the counting window
+	requests  int           // requests in the window
+	failures  int           // failed requests in the window
+	openUntil time.Time     // end of the cool-down while open
+	probes    int           // probe requests in flight while half-open
+	successes int           // successful probes while half-open
+	changed   chan struct{} // closed and replaced when waiters should look again
+}
+
+// hostBreaker keeps one circuit breaker per host
+type hostBreaker struct {
+	mu       sync.Mutex
+	config   BreakerConfig
+	breakers map[string]*circuitBreaker
+}
+
+// newHostBreaker creates the breakers, nil when breaking is disabled
+func newHostBreaker(config BreakerConfig) *hostBreaker {
+	if config.FailureRatio <= 0 {
+		return nil
+	}
+
+	config.MinRequests = max(config.MinRequests, 1)
+	config.HalfOpenProbes = max(config.HalfOpenProbes, 1)
+	if config.Window <= 0 {
+		config.Window = time.Minute
+	}
+
+	return &hostBreaker{
+		config:   config,
+		breakers: make(map[string]*circuitBreaker),
+	}
+}
+
+// breaker returns the breaker of a host, creating it on first use
+func (h *hostBreaker) breaker(host string) *circuitBreaker {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	b, ok := h.breakers[host]
+	if !ok {
+		b = &circuitBreaker{
+			start:   time.Now(),
+			changed: make(chan struct{}),
+		}
+		h.breakers[host] = b
+	}
+
+	return b
+}
+
+// Allow reserves a request to host, failing with ErrCircuitOpen while the
+// breaker is open or every probe slot is taken
+func (h *hostBreaker) Allow(host string) error {
+	b := h.breaker(host)
+	now := time.Now()
+
+	b.mu.Lock()
+	from := b.state
+	if b.state == BreakerOpen && !now.Before(b.openUntil) {
+		h.transition(b, BreakerHalfOpen, now)
+	}
+
+	var err error
+	switch b.state {
+	case BreakerOpen:
+		err = ErrCircuitOpen
+	case BreakerHalfOpen:
+		if b.probes >= h.config.HalfOpenProbes {
+			err = ErrCircuitOpen
+		} else {
+			b.probes++
+		}
+	}
+	to := b.state
+	b.mu.Unlock()
+
+	h.notify(host, from, to)
+	return err
+}
+
+// Record reports the outcome of a request allowed by Allow
+func (h *hostBreaker) Record(host string, success bool) {
+	b := h.breaker(host)
+	now := time.Now()
+
+	b.mu.Lock()
+	from := b.state
+	switch b.state {
+	case BreakerClosed:
+		if now.Sub(b.start) > h.config.Window {
+			b.start, b.requests, b.failures = now, 0, 0
+		}
+		b.requests++
+		if !success {
+			b.failures++
+		}
+		if b.requests >= h.config.MinRequests && float64(b.failures)/float64(b.requests) >= h.config.FailureRatio {
+			h.transition(b, BreakerOpen, now)
+		}
+	case BreakerHalfOpen:
+		b.probes = max(b.probes-1, 0)
+		if !success {
+			h.transition(b, BreakerOpen, now)
+			break
+		}
+		b.successes++
+		if b.successes >= h.config.HalfOpenProbes {
+			h.transition(b, BreakerClosed, now)
+		} else {
+			b.wake()
+		}
+	}
+	to := b.state
+	b.mu.Unlock()
+
+	h.notify(host, from, to)
+}
+
+// Release frees a probe slot of a request that ended without telling
+// anything about the host, e.g. because its context was cancelled
+func (h *hostBreaker) Release(host string) {
+	b := h.breaker(host)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerHalfOpen {
+		b.probes = max(b.probes-1, 0)
+		b.wake()
+	}
+}
+
+// transition moves a locked breaker to a new state and wakes its waiters
+func (h *hostBreaker) transition(b *circuitBreaker, to BreakerState, now time.Time) {
+	b.state = to
+	b.start, b.requests, b.failures = now, 0, 0
+	b.probes, b.successes = 0, 0
+	if to == BreakerOpen {
+		b.openUntil = now.Add(h.config.Cooldown)
+	}
+
+	b.wake()
+}
+
+// wake releases the goroutines waiting on a locked breaker
+func (b *circuitBreaker) wake() {
+	close(b.changed)
+	b.changed = make(chan struct{})
+}
+
+// notify reports a state change to the configured callback
+func (h *hostBreaker) notify(host string, from, to BreakerState) {
+	if from != to && h.config.OnStateChange != nil {
+		h.config.OnStateChange(host, from, to)
+	}
+}
+
+// State returns the state of a host's breaker
+func (h *hostBreaker) State(host string) BreakerState {
+	b := h.breaker(host)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen && !time.Now().Before(b.openUntil) {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
+
+// Acquire blocks until the breaker of host lets a request through and
+// reserves it like Allow, or until ctx is done. Waiters that lose the race
+// for a probe slot go back to waiting instead of failing.
+func (h *hostBreaker) Acquire(ctx context.Context, host string) error {
+	for {
+		if err := h.Wait(ctx, host); err != nil {
+			return err
+		}
+		if err := h.Allow(host); !errors.Is(err, ErrCircuitOpen) {
+			return err
+		}
+	}
+}
+
+// Wait blocks until the breaker of host lets requests through again or ctx
+// is done. Probes are not reserved, so Allow may still reject the request;
+// use Acquire to wait for a reservation.
+func (h *hostBreaker) Wait(ctx context.Context, host string) error {
+	b := h.breaker(host)
+
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		var wait time.Duration
+		switch {
+		case b.state == BreakerOpen && now.Before(b.openUntil):
+			wait = b.openUntil.Sub(now)
+		case b.state == BreakerHalfOpen && b.probes >= h.config.HalfOpenProbes:
+			wait = -1 // until the probes have finished
+		}
+		changed := b.changed
+		b.mu.Unlock()
+
+		if wait == 0 {
+			return ctx.Err()
+		}
+
+		var (
+			timer   *time.Timer
+			timeout <-chan time.Time
+		)
+		if wait > 0 {
+			timer = time.NewTimer(wait)
+			timeout = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-changed:
+		case <-timeout:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
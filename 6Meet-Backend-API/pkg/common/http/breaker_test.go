@@ -0,0 +1,193 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testHost = "example.org"
+
+func testBreaker(cooldown time.Duration, probes int) *hostBreaker {
+	return newHostBreaker(BreakerConfig{
+		FailureRatio:   0.5,
+		MinRequests:    2,
+		Window:         time.Minute,
+		Cooldown:       cooldown,
+		HalfOpenProbes: probes,
+	})
+}
+
+// trip opens the breaker of testHost with failed requests
+func trip(t *testing.T, h *hostBreaker) {
+	t.Helper()
+	for i := 0; i < 2; i++ {
+		if err := h.Allow(testHost); err != nil {
+			t.Fatalf("Allow() error = %v", err)
+		}
+		h.Record(testHost, false)
+	}
+	if state := h.State(testHost); state != BreakerOpen {
+		t.Fatalf("state = %v; want open", state)
+	}
+}
+
+func TestBreakerStateTransitions(t *testing.T) {
+	var changes []BreakerState
+	h := testBreaker(20*time.Millisecond, 2)
+	h.config.OnStateChange = func(host string, from, to BreakerState) {
+		changes = append(changes, to)
+	}
+
+	trip(t, h)
+	if err := h.Allow(testHost); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Allow() while open error = %v; want ErrCircuitOpen", err)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	for i := 0; i < 2; i++ {
+		if err := h.Allow(testHost); err != nil {
+			t.Fatalf("probe Allow() error = %v", err)
+		}
+	}
+	if err := h.Allow(testHost); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Allow() beyond the probes error = %v; want ErrCircuitOpen", err)
+	}
+
+	h.Record(testHost, true)
+	h.Record(testHost, true)
+	if state := h.State(testHost); state != BreakerClosed {
+		t.Errorf("state after successful probes = %v; want closed", state)
+	}
+
+	want := []BreakerState{BreakerOpen, BreakerHalfOpen, BreakerClosed}
+	if len(changes) != len(want) {
+		t.Fatalf("state changes = %v; want %v", changes, want)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("state changes = %v; want %v", changes, want)
+			break
+		}
+	}
+}
+
+func TestBreakerFailedProbeReopens(t *testing.T) {
+	h := testBreaker(10*time.Millisecond, 1)
+	trip(t, h)
+
+	time.Sleep(20 * time.Millisecond)
+	if err := h.Allow(testHost); err != nil {
+		t.Fatalf("probe Allow() error = %v", err)
+	}
+	h.Record(testHost, false)
+
+	if state := h.State(testHost); state != BreakerOpen {
+		t.Errorf("state after a failed probe = %v; want open", state)
+	}
+}
+
+func TestBreakerAcquireReservesProbe(t *testing.T) {
+	h := testBreaker(10*time.Millisecond, 1)
+	trip(t, h)
+
+	const waiters = 5
+	var (
+		wg       sync.WaitGroup
+		acquired atomic.Int32
+		inFlight atomic.Int32
+	)
+	for i := 0; i < waiters; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			if err := h.Acquire(ctx, testHost); err != nil {
+				t.Errorf("Acquire() error = %v", err)
+				return
+			}
+			if inFlight.Add(1) > 1 && h.State(testHost) == BreakerHalfOpen {
+				t.Error("more probes in flight than the breaker allows")
+			}
+			acquired.Add(1)
+			time.Sleep(time.Millisecond)
+			inFlight.Add(-1)
+			h.Record(testHost, true)
+		}()
+	}
+	wg.Wait()
+
+	if acquired.Load() != waiters {
+		t.Errorf("%d of %d waiters acquired the host", acquired.Load(), waiters)
+	}
+}
+
+func TestBreakerAcquireHonoursContext(t *testing.T) {
+	h := testBreaker(time.Hour, 1)
+	trip(t, h)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := h.Acquire(ctx, testHost); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Acquire() error = %v; want context.DeadlineExceeded", err)
+	}
+}
+
+func TestBreakerReleaseFreesProbe(t *testing.T) {
+	h := testBreaker(10*time.Millisecond, 1)
+	trip(t, h)
+
+	time.Sleep(20 * time.Millisecond)
+	if err := h.Allow(testHost); err != nil {
+		t.Fatalf("probe Allow() error = %v", err)
+	}
+	h.Release(testHost)
+
+	if err := h.Allow(testHost); err != nil {
+		t.Errorf("Allow() after Release error = %v", err)
+	}
+}
+
+func TestRequestWithRetryWaitsForOpenBreaker(t *testing.T) {
+	var failing atomic.Bool
+	failing.Store(true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.CompareAndSwap(true, false) {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	pool := NewHTTPClientPool(&HTTPClientConfig{
+		Timeout: time.Second,
+		Breaker: BreakerConfig{
+			FailureRatio: 0.5,
+			MinRequests:  1,
+			Cooldown:     20 * time.Millisecond,
+		},
+	})
+
+	// The first attempt opens the breaker; the retry waits out the cool-down
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	resp, err := pool.RequestWithRetry(context.Background(), req, 2)
+	if err != nil {
+		t.Fatalf("RequestWithRetry() error = %v", err)
+	}
+	resp.Body.Close()
+
+	host, _ := url.Parse(server.URL)
+	if state := pool.BreakerState(host.Host); state != BreakerClosed {
+		t.Errorf("state after a successful probe = %v; want closed", state)
+	}
+}
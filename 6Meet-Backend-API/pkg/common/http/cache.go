@@ -0,0 +1,472 @@
+package http
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cacheEntry is a stored response
+type cacheEntry struct {
+	Key          string      `json:"key"`
+	StatusCode   int         `json:"status_code"`
+	Header       http.Header `json:"header"`
+	Body         []byte      `json:"body"`
+	ETag         string      `json:"etag,omitempty"`
+	LastModified string      `json:"last_modified,omitempty"`
+	ExpiresAt    time.Time   `json:"expires_at"`
+}
+
+// fresh reports whether the entry can be served without asking the server
+func (e *cacheEntry) fresh(now time.Time) bool {
+	return now.Before(e.ExpiresAt)
+}
+
+// revalidatable reports whether the server can confirm the entry is unchanged
+func (e *cacheEntry) revalidatable() bool {
+	return e.ETag != "" || e.LastModified != ""
+}
+
+// size approximates the memory held by the entry
+func (e *cacheEntry) size() int64 {
+	n := len(e.Key) + len(e.Body) + len(e.ETag) + len(e.LastModified)
+	for k, values := range e.Header {
+		n += len(k)
+		for _, v := range values {
+			n += len(v)
+		}
+	}
+	return int64(n)
+}
+
+// response rebuilds an HTTP response from the entry
+func (e *cacheEntry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode)),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+// conditional adds the validators of the entry to a request
+func (e *cacheEntry) conditional(req *http.Request) {
+	if e.ETag != "" {
+		req.Header.Set("If-None-Match", e.ETag)
+	}
+	if e.LastModified != "" {
+		req.Header.Set("If-Modified-Since", e.LastModified)
+	}
+}
+
+// lruCache keeps entries in memory up to a byte budget, evicting the least
+// recently used ones first
+type lruCache struct {
+	mu       sync.Mutex
+	maxBytes int64
+	bytes    int64
+	order    *list.List // front is the most recently used
+	items    map[string]*list.Element
+}
+
+// newLRUCache creates an in-memory cache holding at most maxBytes
+func newLRUCache(maxBytes int64) *lruCache {
+	return &lruCache{
+		maxBytes: maxBytes,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+func (c *lruCache) get(key string) (*cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*cacheEntry), true
+}
+
+func (c *lruCache) set(entry *cacheEntry) {
+	size := entry.size()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.removeLocked(entry.Key)
+	if size > c.maxBytes {
+		return
+	}
+
+	c.items[entry.Key] = c.order.PushFront(entry)
+	c.bytes += size
+
+	for c.bytes > c.maxBytes {
+		oldest := c.order.Back()
+		c.removeLocked(oldest.Value.(*cacheEntry).Key)
+	}
+}
+
+func (c *lruCache) delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.removeLocked(key)
+}
+
+func (c *lruCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.order.Init()
+	c.items = make(map[string]*list.Element)
+	c.bytes = 0
+}
+
+func (c *lruCache) removeLocked(key string) {
+	elem, ok := c.items[key]
+	if !ok {
+		return
+	}
+	c.order.Remove(elem)
+	delete(c.items, key)
+	c.bytes -= elem.Value.(*cacheEntry).size()
+}
+
+// diskCache stores one JSON file per entry so responses survive restarts.
+// The directory is kept under a byte budget by removing the least recently
+// used files, by modification time, once a write exceeds it.
+type diskCache struct {
+	dir      string
+	maxBytes int64
+
+	mu     sync.Mutex // guards bytes and loaded
+	bytes  int64      // size of the entry files
+	loaded bool       // bytes has been computed from the directory
+}
+
+// diskLowWater is the share of the budget eviction frees down to, so
+// consecutive writes do not each rescan the directory
+const diskLowWater = 0.9
+
+// path returns the file of a key
+func (c *diskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (c *diskCache) get(key string) (*cacheEntry, bool) {
+	path := c.path(key)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Key != key {
+		return nil, false
+	}
+
+	// Mark the file as recently used for eviction
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+
+	return &entry, true
+}
+
+// set writes the entry through a temporary file so readers never see a
+// partial file, then evicts old entries when the budget is exceeded
+func (c *diskCache) set(entry *cacheEntry) error {
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create cache directory %s: %w", c.dir, err)
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) > c.maxBytes {
+		c.delete(entry.Key)
+		return nil
+	}
+
+	tmp, err := os.CreateTemp(c.dir, "entry-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.load()
+	path := c.path(entry.Key)
+	previous := fileSize(path)
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+	c.bytes += int64(len(data)) - previous
+
+	if c.bytes > c.maxBytes {
+		c.evict()
+	}
+	return nil
+}
+
+func (c *diskCache) delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	path := c.path(key)
+	size := fileSize(path)
+	if os.Remove(path) == nil && c.loaded {
+		c.bytes -= size
+	}
+}
+
+func (c *diskCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	files, _ := filepath.Glob(filepath.Join(c.dir, "*.json"))
+	for _, file := range files {
+		os.Remove(file)
+	}
+	c.bytes, c.loaded = 0, true
+}
+
+// load computes the size of the directory once. The caller must hold c.mu.
+func (c *diskCache) load() {
+	if c.loaded {
+		return
+	}
+	c.loaded = true
+
+	c.bytes = 0
+	for _, file := range c.files() {
+		c.bytes += file.size
+	}
+}
+
+// evict removes the least recently used files until the directory is below
+// the low-water mark. The caller must hold c.mu.
+func (c *diskCache) evict() {
+	files := c.files()
+	slices.SortFunc(files, func(a, b diskFile) int {
+		return a.modTime.Compare(b.modTime)
+	})
+
+	c.bytes = 0
+	for _, file := range files {
+		c.bytes += file.size
+	}
+
+	target := int64(float64(c.maxBytes) * diskLowWater)
+	for _, file := range files {
+		if c.bytes <= target {
+			break
+		}
+		if os.Remove(file.path) == nil {
+			c.bytes -= file.size
+		}
+	}
+}
+
+// diskFile is an entry file found in the cache directory
+type diskFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// files lists the entry files of the cache directory
+func (c *diskCache) files() []diskFile {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return nil
+	}
+
+	files := make([]diskFile, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, diskFile{
+			path:    filepath.Join(c.dir, entry.Name()),
+			size:    info.Size(),
+			modTime: info.ModTime(),
+		})
+	}
+	return files
+}
+
+// fileSize returns the size of a file, 0 when it does not exist
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// responseCache caches successful GET responses in memory and optionally on
+// disk. Entries are served directly until their TTL expires and are then
+// revalidated with If-None-Match / If-Modified-Since when the server sent
+// an ETag or Last-Modified header.
+type responseCache struct {
+	ttl    time.Duration
+	memory *lruCache
+	disk   *diskCache // nil when the cache is memory only
+}
+
+// newResponseCache creates a response cache. dir enables the disk backend,
+// which holds at most diskMaxBytes.
+func newResponseCache(ttl time.Duration, maxBytes int64, dir string, diskMaxBytes int64) *responseCache {
+	c := &responseCache{
+		ttl:    ttl,
+		memory: newLRUCache(maxBytes),
+	}
+	if dir != "" {
+		c.disk = &diskCache{dir: dir, maxBytes: diskMaxBytes}
+	}
+
+	return c
+}
+
+// key returns the cache key of a request, empty when it is not cacheable
+func (c *responseCache) key(req *http.Request) string {
+	if req.Method != http.MethodGet || req.Header.Get("Authorization") != "" {
+		return ""
+	}
+	return req.URL.String()
+}
+
+// get looks an entry up in memory, then on disk
+func (c *responseCache) get(key string) (*cacheEntry, bool) {
+	if entry, ok := c.memory.get(key); ok {
+		return entry, true
+	}
+	if c.disk == nil {
+		return nil, false
+	}
+
+	entry, ok := c.disk.get(key)
+	if ok {
+		c.memory.set(entry)
+	}
+	return entry, ok
+}
+
+// set stores an entry in every backend
+func (c *responseCache) set(entry *cacheEntry) error {
+	c.memory.set(entry)
+	if c.disk == nil {
+		return nil
+	}
+	return c.disk.set(entry)
+}
+
+// delete removes an entry from every backend
+func (c *responseCache) delete(key string) {
+	c.memory.delete(key)
+	if c.disk != nil {
+		c.disk.delete(key)
+	}
+}
+
+// clear removes every entry
+func (c *responseCache) clear() {
+	c.memory.clear()
+	if c.disk != nil {
+		c.disk.clear()
+	}
+}
+
+// store caches a 200 response. The body is read into memory and the
+// returned response replays it. MediaWiki reports API errors with a 200
+// status, so responses carrying an API error are passed through uncached.
+func (c *responseCache) store(key string, resp *http.Response) (*http.Response, error) {
+	if resp.StatusCode != http.StatusOK ||
+		strings.Contains(resp.Header.Get("Cache-Control"), "no-store") ||
+		resp.Header.Get(headerMediaWikiError) != "" {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if isAPIError(body) {
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		return resp, nil
+	}
+
+	entry := &cacheEntry{
+		Key:          key,
+		StatusCode:   resp.StatusCode,
+		Header:       resp.Header.Clone(),
+		Body:         body,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		ExpiresAt:    time.Now().Add(c.ttl),
+	}
+	// A failed disk write only costs a future download
+	_ = c.set(entry)
+
+	return entry.response(resp.Request), nil
+}
+
+// refresh extends the TTL of an entry the server confirmed as unchanged
+func (c *responseCache) refresh(entry *cacheEntry) *cacheEntry {
+	refreshed := *entry
+	refreshed.ExpiresAt = time.Now().Add(c.ttl)
+	_ = c.set(&refreshed)
+	return &refreshed
+}
+
+// isAPIError reports whether a JSON body is a MediaWiki API error, i.e. an
+// object with a top-level "error" member
+func isAPIError(body []byte) bool {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return false
+	}
+
+	var result struct {
+		Error json.RawMessage `json:"error"`
+	}
+	return json.Unmarshal(trimmed, &result) == nil && len(result.Error) > 0 && string(result.Error) != "null"
+}
@@ -0,0 +1,178 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testEntry(key string, bodySize int) *cacheEntry {
+	return &cacheEntry{
+		Key:        key,
+		StatusCode: http.StatusOK,
+		Body:       []byte(strings.Repeat("x", bodySize)),
+		ExpiresAt:  time.Now().Add(time.Hour),
+	}
+}
+
+func TestLRUCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	a, b, c := testEntry("a", 40), testEntry("b", 40), testEntry("c", 40)
+	cache := newLRUCache(a.size() * 2)
+
+	cache.set(a)
+	cache.set(b)
+	cache.get("a") // a is now more recent than b
+	cache.set(c)
+
+	if _, ok := cache.get("b"); ok {
+		t.Error("least recently used entry b was kept")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := cache.get(key); !ok {
+			t.Errorf("entry %s was evicted", key)
+		}
+	}
+
+	cache.set(testEntry("huge", 1000))
+	if _, ok := cache.get("huge"); ok {
+		t.Error("entry larger than the budget was stored")
+	}
+}
+
+func TestDiskCacheStaysWithinBudget(t *testing.T) {
+	dir := t.TempDir()
+	cache := &diskCache{dir: dir, maxBytes: 2000}
+
+	for i := 0; i < 20; i++ {
+		if err := cache.set(testEntry(fmt.Sprintf("key-%d", i), 200)); err != nil {
+			t.Fatalf("set() error = %v", err)
+		}
+	}
+
+	var total int64
+	for _, file := range cache.files() {
+		total += file.size
+	}
+	if total > cache.maxBytes {
+		t.Errorf("cache directory holds %d bytes; want at most %d", total, cache.maxBytes)
+	}
+	if _, ok := cache.get("key-19"); !ok {
+		t.Error("most recent entry was evicted")
+	}
+	if _, ok := cache.get("key-0"); ok {
+		t.Error("oldest entry was kept")
+	}
+
+	// A fresh cache over the same directory accounts for the existing files
+	reopened := &diskCache{dir: dir, maxBytes: 1000}
+	if err := reopened.set(testEntry("reopened", 200)); err != nil {
+		t.Fatalf("set() error = %v", err)
+	}
+	total = 0
+	for _, file := range reopened.files() {
+		total += file.size
+	}
+	if total > reopened.maxBytes {
+		t.Errorf("reopened cache directory holds %d bytes; want at most %d", total, reopened.maxBytes)
+	}
+
+	reopened.clear()
+	if files, _ := filepath.Glob(filepath.Join(dir, "*.json")); len(files) != 0 {
+		t.Errorf("clear() left %d files", len(files))
+	}
+}
+
+func TestResponseCacheSkipsAPIErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		apiError string // MediaWiki-API-Error header value
+		body     string
+		cached   bool
+	}{
+		{name: "result", body: `{"query":{"pages":[]}}`, cached: true},
+		{name: "error body", body: `{"error":{"code":"badtitle"}}`, cached: false},
+		{name: "error header", apiError: "badtitle", body: `{}`, cached: false},
+		{name: "null error", body: `{"error":null,"query":{}}`, cached: true},
+		{name: "not json", body: `<html>`, cached: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := newResponseCache(time.Hour, 1<<20, "", 0)
+			resp := &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{},
+				Body:       io.NopCloser(strings.NewReader(tt.body)),
+			}
+			if tt.apiError != "" {
+				resp.Header.Set(headerMediaWikiError, tt.apiError)
+			}
+
+			stored, err := cache.store("key", resp)
+			if err != nil {
+				t.Fatalf("store() error = %v", err)
+			}
+			if body, _ := io.ReadAll(stored.Body); string(body) != tt.body {
+				t.Errorf("returned body = %q; want %q", body, tt.body)
+			}
+			if _, ok := cache.get("key"); ok != tt.cached {
+				t.Errorf("cached = %v; want %v", ok, tt.cached)
+			}
+		})
+	}
+}
+
+func TestRequestWithRetryRevalidates(t *testing.T) {
+	var requests, notModified atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		fmt.Fprint(w, "page")
+	}))
+	defer server.Close()
+
+	pool := NewHTTPClientPool(&HTTPClientConfig{
+		Timeout:         time.Second,
+		EnableCache:     true,
+		CacheExpiration: 20 * time.Millisecond,
+		CacheDir:        filepath.Join(t.TempDir(), "cache"),
+	})
+
+	get := func() string {
+		req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+		resp, err := pool.RequestWithRetry(context.Background(), req, 1)
+		if err != nil {
+			t.Fatalf("RequestWithRetry() error = %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+
+	get()
+	if body := get(); body != "page" || requests.Load() != 1 {
+		t.Errorf("fresh hit = %q after %d requests; want the cached page after 1", body, requests.Load())
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if body := get(); body != "page" || notModified.Load() != 1 {
+		t.Errorf("expired hit = %q after %d revalidations; want the cached page after 1", body, notModified.Load())
+	}
+
+	if _, err := os.Stat(pool.cache.disk.dir); err != nil {
+		t.Errorf("disk cache directory missing: %v", err)
+	}
+}
@@ -0,0 +1,278 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ErrCassetteMiss is returned in replay mode for a request the cassette does not contain
+var ErrCassetteMiss = errors.New("no recorded response for request")
+
+// CassetteMode selects what a cassette transport does with requests
+type CassetteMode string
+
+const (
+	// CassetteOff sends requests normally
+	CassetteOff CassetteMode = ""
+	// CassetteRecord sends requests and appends every exchange to the cassette
+	CassetteRecord CassetteMode = "record"
+	// CassetteReplay answers requests from the cassette without using the network
+	CassetteReplay CassetteMode = "replay"
+)
+
+// cassetteRequest is the recorded part of a request used for matching.
+// Bodies are kept as bytes, base64 encoded in the cassette, so binary and
+// compressed bodies survive the round trip.
+type cassetteRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   []byte `json:"body,omitempty"`
+}
+
+// key identifies requests that should get the same response
+func (r cassetteRequest) key() string {
+	return r.Method + " " + r.URL + "\n" + string(r.Body)
+}
+
+// cassetteResponse is a recorded response
+type cassetteResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+}
+
+// interaction is a recorded request/response pair
+type interaction struct {
+	Request  cassetteRequest  `json:"request"`
+	Response cassetteResponse `json:"response"`
+}
+
+// CassetteTransport is an http.RoundTripper that records exchanges to a
+// cassette file or replays them from it, so tests can run against real
+// responses without the network. A cassette holds one JSON interaction per
+// line. Repeated requests are replayed in recorded order and the last
+// response is reused once they run out.
+type CassetteTransport struct {
+	path string
+	mode CassetteMode
+	next http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []interaction  // replayed interactions
+	replayed     map[string]int // replay position per request key
+	file         *os.File       // cassette appended to while recording
+}
+
+// NewCassetteTransport opens the cassette at path. Record mode sends
+// requests through next and appends them to the cassette, keeping what it
+// already holds; replay mode requires an existing cassette.
+func NewCassetteTransport(path string, mode CassetteMode, next http.RoundTripper) (*CassetteTransport, error) {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	t := &CassetteTransport{
+		path:     path,
+		mode:     mode,
+		next:     next,
+		replayed: make(map[string]int),
+	}
+
+	var err error
+	switch mode {
+	case CassetteRecord:
+		t.file, err = openCassette(path)
+	case CassetteReplay:
+		t.interactions, err = loadCassette(path)
+	default:
+		err = fmt.Errorf("unknown cassette mode %q", mode)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// openCassette opens a cassette for appending, creating it if needed
+func openCassette(path string) (*os.File, error) {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cassette directory %s: %w", dir, err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cassette %s: %w", path, err)
+	}
+	return file, nil
+}
+
+// loadCassette reads every interaction of a cassette
+func loadCassette(path string) ([]interaction, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette %s: %w", path, err)
+	}
+	defer file.Close()
+
+	var interactions []interaction
+	decoder := json.NewDecoder(file)
+	for decoder.More() {
+		var i interaction
+		if err := decoder.Decode(&i); err != nil {
+			return nil, fmt.Errorf("failed to decode cassette %s: %w", path, err)
+		}
+		interactions = append(interactions, i)
+	}
+
+	return interactions, nil
+}
+
+// Close closes the cassette file of a recording transport
+func (t *CassetteTransport) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.file == nil {
+		return nil
+	}
+	err := t.file.Close()
+	t.file = nil
+	return err
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *CassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorded, err := recordRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.mode == CassetteReplay {
+		return t.replay(req, recorded)
+	}
+	return t.record(req, recorded)
+}
+
+// replay answers a request from the cassette
+func (t *CassetteTransport) replay(req *http.Request, recorded cassetteRequest) (*http.Response, error) {
+	key := recorded.key()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var matches []*interaction
+	for i := range t.interactions {
+		if t.interactions[i].Request.key() == key {
+			matches = append(matches, &t.interactions[i])
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("%w: %s %s", ErrCassetteMiss, recorded.Method, recorded.URL)
+	}
+
+	pos := min(t.replayed[key], len(matches)-1)
+	t.replayed[key]++
+
+	return matches[pos].Response.response(req), nil
+}
+
+// record sends a request and appends the exchange to the cassette
+func (t *CassetteTransport) record(req *http.Request, recorded cassetteRequest) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	line, err := json.Marshal(interaction{
+		Request: recorded,
+		Response: cassetteResponse{
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header.Clone(),
+			Body:       body,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode interaction: %w", err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.file == nil {
+		return nil, fmt.Errorf("cassette %s is closed", t.path)
+	}
+	// A single write per line keeps interactions whole if recording stops
+	if _, err := t.file.Write(append(line, '\n')); err != nil {
+		return nil, fmt.Errorf("failed to write cassette %s: %w", t.path, err)
+	}
+
+	return resp, nil
+}
+
+// failedTransport fails every request with the error that prevented the
+// real transport from being built
+type failedTransport struct {
+	err error
+}
+
+// RoundTrip implements http.RoundTripper
+func (t failedTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, t.err
+}
+
+// recordRequest captures the matching fields of a request. Query
+// parameters are re-encoded in sorted order so their order does not matter.
+func recordRequest(req *http.Request) (cassetteRequest, error) {
+	u := *req.URL
+	u.RawQuery = u.Query().Encode()
+
+	recorded := cassetteRequest{
+		Method: req.Method,
+		URL:    u.String(),
+	}
+
+	if req.Body == nil || req.Body == http.NoBody {
+		return recorded, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return recorded, fmt.Errorf("failed to read request body: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	recorded.Body = body
+
+	return recorded, nil
+}
+
+// response rebuilds the recorded response for a request
+func (r cassetteResponse) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode)),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        r.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,152 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCassetteReplay(t *testing.T) {
+	pool := NewHTTPClientPool(&HTTPClientConfig{
+		Timeout:      time.Second,
+		CassetteMode: CassetteReplay,
+		CassettePath: filepath.Join("testdata", "wikipedia.jsonl"),
+	})
+
+	get := func(rawURL string) (string, error) {
+		req, _ := http.NewRequest(http.MethodGet, rawURL, nil)
+		resp, err := pool.RequestWithRetry(context.Background(), req, 3)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body), nil
+	}
+
+	const api = "https://en.wikipedia.org/w/api.php"
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{name: "query order ignored", url: api + "?titles=Go&format=json&action=query", want: `{"query":{"pages":[{"pageid":25039021,"title":"Go"}]}}`},
+		{name: "first of repeated", url: api + "?action=query&list=random&format=json", want: `{"query":{"random":[{"title":"Alpha"}]}}`},
+		{name: "second of repeated", url: api + "?action=query&list=random&format=json", want: `{"query":{"random":[{"title":"Beta"}]}}`},
+		{name: "last reused", url: api + "?action=query&list=random&format=json", want: `{"query":{"random":[{"title":"Beta"}]}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := get(tt.url)
+			if err != nil {
+				t.Fatalf("RequestWithRetry() error = %v", err)
+			}
+			if body != tt.want {
+				t.Errorf("body = %s; want %s", body, tt.want)
+			}
+		})
+	}
+
+	if _, err := get(api + "?action=parse"); !errors.Is(err, ErrCassetteMiss) {
+		t.Errorf("unrecorded request error = %v; want ErrCassetteMiss", err)
+	}
+}
+
+func TestCassetteRecordAppends(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.URL.Query().Get("page"))
+	}))
+	defer server.Close()
+
+	seed, err := os.ReadFile(filepath.Join("testdata", "wikipedia.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "cassettes", "recorded.jsonl")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, seed, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	existing, err := loadCassette(path)
+	if err != nil {
+		t.Fatalf("loadCassette() error = %v", err)
+	}
+
+	transport, err := NewCassetteTransport(path, CassetteRecord, nil)
+	if err != nil {
+		t.Fatalf("NewCassetteTransport() error = %v", err)
+	}
+	client := &http.Client{Transport: transport}
+	for _, page := range []string{"a", "b"} {
+		resp, err := client.Get(server.URL + "?page=" + page)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if body, _ := io.ReadAll(resp.Body); string(body) != page {
+			t.Errorf("recorded body = %q; want %q", body, page)
+		}
+		resp.Body.Close()
+	}
+	if err := transport.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	recorded, err := loadCassette(path)
+	if err != nil {
+		t.Fatalf("loadCassette() error = %v", err)
+	}
+	if len(recorded) != len(existing)+2 {
+		t.Fatalf("cassette holds %d interactions; want the %d existing plus 2", len(recorded), len(existing))
+	}
+	if last := recorded[len(recorded)-1].Response.Body; string(last) != "b" {
+		t.Errorf("last recorded body = %q; want %q", last, "b")
+	}
+}
+
+func TestCassetteKeepsBinaryBodies(t *testing.T) {
+	body := []byte{0x1f, 0x8b, 0xff, 0xfe, 0x00, 'a'}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "binary.jsonl")
+	pool := NewHTTPClientPool(&HTTPClientConfig{
+		Timeout:      time.Second,
+		CassetteMode: CassetteRecord,
+		CassettePath: path,
+	})
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	resp, err := pool.RequestWithRetry(context.Background(), req, 1)
+	if err != nil {
+		t.Fatalf("RequestWithRetry() error = %v", err)
+	}
+	resp.Body.Close()
+	if err := pool.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	transport, err := NewCassetteTransport(path, CassetteReplay, nil)
+	if err != nil {
+		t.Fatalf("NewCassetteTransport() error = %v", err)
+	}
+	resp, err = (&http.Client{Transport: transport}).Get(server.URL)
+	if err != nil {
+		t.Fatalf("replayed Get() error = %v", err)
+	}
+	defer resp.Body.Close()
+	if got, _ := io.ReadAll(resp.Body); !bytes.Equal(got, body) {
+		t.Errorf("replayed body = %x; want %x", got, body)
+	}
+}
@@ -2,35 +2,67 @@ package http
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
-	"sync"
+	"strconv"
 	"time"
 )
 
+const (
+	// headerMediaWikiError carries the MediaWiki API error code, e.g. "maxlag"
+	headerMediaWikiError = "MediaWiki-API-Error"
+
+	// defaultThrottleDelay is used when a throttled response has no usable Retry-After
+	defaultThrottleDelay = 5 * time.Second
+
+	// defaultCacheMaxBytes is the memory budget of the response cache when none is configured
+	defaultCacheMaxBytes = 64 << 20
+
+	// defaultCacheDiskMaxBytes is the disk budget of the response cache when none is configured
+	defaultCacheDiskMaxBytes = 1 << 30
+)
+
 type HTTPClientPool struct {
-	client *http.Client
-	mu     sync.RWMutex
-	cache  map[string]interface{}
+	client        *http.Client
+	limiter       *hostLimiter
+	breaker       *hostBreaker // nil when circuit breaking is disabled
+	maxRetryAfter time.Duration
+	cache         *responseCache     // nil when caching is disabled
+	cassette      *CassetteTransport // nil when no cassette is used
 }
 
 type HTTPClientConfig struct {
-	Timeout         time.Duration
-	MaxIdleConns    int
-	IdleConnTimeout time.Duration
-	MaxConnsPerHost int
-	EnableCache     bool
-	CacheExpiration time.Duration
+	Timeout           time.Duration
+	MaxIdleConns      int
+	IdleConnTimeout   time.Duration
+	MaxConnsPerHost   int
+	EnableCache       bool
+	CacheExpiration   time.Duration // time a cached response is served without revalidation
+	CacheMaxBytes     int64         // memory budget of the response cache
+	CacheDir          string        // directory persisting cached responses, empty keeps them in memory only
+	CacheDiskMaxBytes int64         // disk budget of the response cache
+	RateLimit         float64       // requests per second per host, 0 disables limiting
+	RateBurst         int           // requests allowed in a burst per host
+	MaxRetryAfter     time.Duration // upper bound for server-requested back-off, 0 means no bound
+	Breaker           BreakerConfig // per-host circuit breaker, disabled without a failure ratio
+	Transport         http.RoundTripper
+	CassetteMode      CassetteMode // record or replay requests, see CassetteTransport
+	CassettePath      string       // cassette file used by CassetteMode
 }
 
 // DefaultHTTPConfig returns default configuration for HTTP client pool
 func DefaultHTTPConfig() *HTTPClientConfig {
 	return &HTTPClientConfig{
-		Timeout:         30 * time.Second,
-		MaxIdleConns:    100,
-		IdleConnTimeout: 90 * time.Second,
-		MaxConnsPerHost: 10,
-		EnableCache:     true,
-		CacheExpiration: 5 * time.Minute,
+		Timeout:           30 * time.Second,
+		MaxIdleConns:      100,
+		IdleConnTimeout:   90 * time.Second,
+		MaxConnsPerHost:   10,
+		EnableCache:       true,
+		CacheExpiration:   5 * time.Minute,
+		CacheMaxBytes:     defaultCacheMaxBytes,
+		CacheDiskMaxBytes: defaultCacheDiskMaxBytes,
+		MaxRetryAfter:     time.Minute,
 	}
 }
 
@@ -40,69 +72,281 @@ func NewHTTPClientPool(config *HTTPClientConfig) *HTTPClientPool {
 		config = DefaultHTTPConfig()
 	}
 
+	transport, cassette := newTransport(config)
 	client := &http.Client{
-		Timeout: config.Timeout,
-		Transport: &http.Transport{
+		Timeout:   config.Timeout,
+		Transport: transport,
+	}
+
+	pool := &HTTPClientPool{
+		client:        client,
+		cassette:      cassette,
+		limiter:       newHostLimiter(config.RateLimit, config.RateBurst),
+		breaker:       newHostBreaker(config.Breaker),
+		maxRetryAfter: config.MaxRetryAfter,
+	}
+
+	if config.EnableCache {
+		maxBytes := config.CacheMaxBytes
+		if maxBytes <= 0 {
+			maxBytes = defaultCacheMaxBytes
+		}
+		diskMaxBytes := config.CacheDiskMaxBytes
+		if diskMaxBytes <= 0 {
+			diskMaxBytes = defaultCacheDiskMaxBytes
+		}
+		pool.cache = newResponseCache(config.CacheExpiration, maxBytes, config.CacheDir, diskMaxBytes)
+	}
+
+	return pool
+}
+
+// newTransport builds the round tripper of the pool: the configured one or
+// a pooled transport, wrapped in a cassette transport when recording or
+// replaying. The cassette transport is returned as well so the pool can
+// close it. A cassette that cannot be opened fails every request.
+func newTransport(config *HTTPClientConfig) (http.RoundTripper, *CassetteTransport) {
+	transport := config.Transport
+	if transport == nil {
+		transport = &http.Transport{
 			MaxIdleConns:        config.MaxIdleConns,
 			MaxIdleConnsPerHost: config.MaxConnsPerHost,
 			IdleConnTimeout:     config.IdleConnTimeout,
-		},
+		}
 	}
 
-	return &HTTPClientPool{
-		client: client,
-		cache:  make(map[string]interface{}),
+	if config.CassetteMode == CassetteOff {
+		return transport, nil
+	}
+
+	cassette, err := NewCassetteTransport(config.CassettePath, config.CassetteMode, transport)
+	if err != nil {
+		return failedTransport{err: err}, nil
+	}
+	return cassette, cassette
+}
+
+// Close closes the cassette the pool records to and the idle connections.
+// Requests made after Close fail while recording.
+func (p *HTTPClientPool) Close() error {
+	p.client.CloseIdleConnections()
+	if p.cassette == nil {
+		return nil
 	}
+	return p.cassette.Close()
 }
 
-// RequestWithRetry performs an HTTP request with retry logic
+// RequestWithRetry performs an HTTP request with retry logic. Requests are
+// rate limited per host; throttled responses (429, 503 with Retry-After and
+// MediaWiki maxlag errors) pause the host for the requested delay. With the
+// cache enabled, GET responses are served from the cache while fresh and
+// revalidated with their ETag or Last-Modified once expired.
 func (p *HTTPClientPool) RequestWithRetry(ctx context.Context, req *http.Request, maxRetries int) (*http.Response, error) {
-	var lastErr error
-	for attempt := 0; attempt < maxRetries; attempt++ {
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
+	var key string
+	if p.cache != nil {
+		key = p.cache.key(req)
+	}
+	if key == "" {
+		return p.do(ctx, req, maxRetries)
+	}
+
+	entry, cached := p.cache.get(key)
+	if cached {
+		switch {
+		case entry.fresh(time.Now()):
+			return entry.response(req), nil
+		case entry.revalidatable():
+			req = req.Clone(ctx)
+			entry.conditional(req)
 		default:
-			resp, err := p.client.Do(req)
-			if err == nil && resp.StatusCode < 500 {
-				return resp, nil
-			}
-			if err != nil {
-				lastErr = err
+			p.cache.delete(key)
+			cached = false
+		}
+	}
+
+	resp, err := p.do(ctx, req, maxRetries)
+	if err != nil {
+		return nil, err
+	}
+
+	if cached && resp.StatusCode == http.StatusNotModified {
+		drainBody(resp)
+		return p.cache.refresh(entry).response(req), nil
+	}
+
+	return p.cache.store(key, resp)
+}
+
+// do sends a request, making up to maxRetries attempts. Transport errors
+// and 502-504 responses are retried after a jittered back-off; throttled
+// responses pause the host for the delay the server asked for. While the
+// host's circuit breaker is open, attempts wait for it to let them through
+// instead of failing. Discarded responses are drained so their connections
+// are reused, and the final failure is reported as a RetryError.
+func (p *HTTPClientPool) do(ctx context.Context, req *http.Request, maxRetries int) (*http.Response, error) {
+	host := req.URL.Host
+	attempts := max(maxRetries, 1)
+
+	var (
+		lastErr    error
+		lastStatus int
+		delay      time.Duration
+	)
+	fail := func(attempts int, err error) error {
+		return &RetryError{
+			Method:     req.Method,
+			URL:        req.URL.String(),
+			Attempts:   attempts,
+			StatusCode: lastStatus,
+			Err:        err,
+		}
+	}
+
+	for attempt := 0; attempt < attempts; attempt++ {
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+		if err := p.limiter.Wait(ctx, host); err != nil {
+			return nil, err
+		}
+
+		r, err := attemptRequest(ctx, req, attempt)
+		if err != nil {
+			return nil, fail(attempt, errors.Join(lastErr, err))
+		}
+
+		// Reserve the host last, so no failure before sending holds a probe slot
+		if p.breaker != nil {
+			if err := p.breaker.Acquire(ctx, host); err != nil {
+				return nil, err
 			}
-			// Exponential backoff
-			backoff := time.Duration(1<<attempt) * time.Second
-			timer := time.NewTimer(backoff)
-			select {
-			case <-ctx.Done():
-				timer.Stop()
+		}
+
+		resp, err := p.client.Do(r)
+		p.recordBreaker(ctx, host, resp, err)
+
+		if err != nil {
+			if ctx.Err() != nil {
 				return nil, ctx.Err()
-			case <-timer.C:
-				continue
 			}
+			lastErr, lastStatus = err, 0
+			if !retryableError(err) {
+				return nil, fail(attempt+1, err)
+			}
+			delay = retryDelay(attempt)
+			continue
+		}
+
+		if pause, throttled := p.throttleDelay(resp, attempt); throttled {
+			lastErr = fmt.Errorf("throttled by %s: %s", host, throttleReason(resp))
+			lastStatus = resp.StatusCode
+			drainBody(resp)
+			p.limiter.Pause(host, pause)
+			delay = 0
+			continue
+		}
+
+		if resp.StatusCode < 500 {
+			return resp, nil
 		}
+
+		lastErr, lastStatus = errors.New(resp.Status), resp.StatusCode
+		drainBody(resp)
+		if !retryableStatus(resp.StatusCode) {
+			return nil, fail(attempt+1, lastErr)
+		}
+		delay = retryDelay(attempt)
+	}
+
+	return nil, fail(attempts, lastErr)
+}
+
+// recordBreaker reports the outcome of an attempt to the host's breaker.
+// Transport errors and 5xx responses count as failures; attempts cut short
+// by the caller's context say nothing about the host.
+func (p *HTTPClientPool) recordBreaker(ctx context.Context, host string, resp *http.Response, err error) {
+	if p.breaker == nil {
+		return
+	}
+	if err != nil && ctx.Err() != nil {
+		p.breaker.Release(host)
+		return
+	}
+	p.breaker.Record(host, err == nil && resp.StatusCode < 500)
+}
+
+// BreakerState returns the circuit breaker state of a host. Hosts are
+// always closed when circuit breaking is disabled.
+func (p *HTTPClientPool) BreakerState(host string) BreakerState {
+	if p.breaker == nil {
+		return BreakerClosed
 	}
-	return nil, lastErr
+	return p.breaker.State(host)
 }
 
-// GetFromCache retrieves data from cache if available
-func (p *HTTPClientPool) GetFromCache(key string) (interface{}, bool) {
-	p.mu.RLock()
-	defer p.mu.RUnlock()
-	val, ok := p.cache[key]
-	return val, ok
+// WaitForHost blocks while the circuit breaker of host is open, so callers
+// can hold back work instead of failing it, or until ctx is done
+func (p *HTTPClientPool) WaitForHost(ctx context.Context, host string) error {
+	if p.breaker == nil {
+		return ctx.Err()
+	}
+	return p.breaker.Wait(ctx, host)
+}
+
+// throttleDelay reports whether the server asked us to slow down and for how long
+func (p *HTTPClientPool) throttleDelay(resp *http.Response, attempt int) (time.Duration, bool) {
+	retryAfter, hasRetryAfter := parseRetryAfter(resp.Header.Get("Retry-After"))
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+	case resp.Header.Get(headerMediaWikiError) == "maxlag":
+	case resp.StatusCode == http.StatusServiceUnavailable && hasRetryAfter:
+	default:
+		return 0, false
+	}
+
+	delay := retryAfter
+	if !hasRetryAfter {
+		delay = defaultThrottleDelay * time.Duration(1<<attempt)
+	}
+	if p.maxRetryAfter > 0 && delay > p.maxRetryAfter {
+		delay = p.maxRetryAfter
+	}
+
+	return delay, true
+}
+
+// throttleReason describes why a response was treated as throttled
+func throttleReason(resp *http.Response) string {
+	if code := resp.Header.Get(headerMediaWikiError); code != "" {
+		return code
+	}
+	return resp.Status
 }
 
-// SetCache stores data in cache
-func (p *HTTPClientPool) SetCache(key string, value interface{}) {
-	p.mu.Lock()
-	defer p.mu.Unlock()
-	p.cache[key] = value
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0), true
+	}
+
+	return 0, false
 }
 
-// ClearCache removes all items from cache
+// ClearCache removes all cached responses
 func (p *HTTPClientPool) ClearCache() {
-	p.mu.Lock()
-	defer p.mu.Unlock()
-	p.cache = make(map[string]interface{})
+	if p.cache != nil {
+		p.cache.clear()
+	}
 }
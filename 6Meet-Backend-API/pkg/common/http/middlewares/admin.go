@@ -0,0 +1,27 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+
+	"github.com/gin-gonic/gin"
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/common/http/response"
+)
+
+// HeaderAdminToken carries the admin token of admin requests
+const HeaderAdminToken = "X-Admin-Token"
+
+// AdminMiddleware only lets requests carrying the admin token through. Every
+// request is rejected while no token is configured.
+func AdminMiddleware(token string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		given := ctx.GetHeader(HeaderAdminToken)
+
+		if token == "" || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			response.ErrorResponse(ctx, response.CodeForbidden, nil)
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
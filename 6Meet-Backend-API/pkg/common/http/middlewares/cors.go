@@ -8,8 +8,9 @@ func CORSMiddleware(ctx *gin.Context) {
 
 	ctx.Header("Access-Control-Allow-Origin", ctx.Request.Header.Get("Origin"))
 	ctx.Header("Access-Control-Allow-Credentials", "true")
-	ctx.Header("Access-Control-Allow-Headers", "Content-Type, Access-Control-Allow-Headers, Authorization, X-Requested-With")
+	ctx.Header("Access-Control-Allow-Headers", "Content-Type, Access-Control-Allow-Headers, Authorization, X-Requested-With, If-Match, If-None-Match, X-Admin-Token")
 	ctx.Header("Access-Control-Allow-Methods", "GET,POST,PUT,PATCH,DELETE,OPTIONS")
+	ctx.Header("Access-Control-Expose-Headers", "ETag")
 
 	if method == "OPTIONS" || method == "HEAD" {
 		ctx.AbortWithStatus(204)
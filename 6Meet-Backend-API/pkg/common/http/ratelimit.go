@@ -0,0 +1,116 @@
+package http
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// tokenBucket is a token-bucket limiter that can also be paused, e.g. when
+// a server asks clients to back off with Retry-After
+type tokenBucket struct {
+	mu          sync.Mutex
+	rate        float64   // tokens added per second, <= 0 means unlimited
+	burst       float64   // bucket capacity
+	tokens      float64   // available tokens, negative when reserved ahead
+	last        time.Time // last refill
+	pausedUntil time.Time // no tokens are handed out before this time
+}
+
+// reserve takes one token and returns how long the caller must wait before using it
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var wait time.Duration
+
+	if b.rate > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+		b.tokens--
+
+		if b.tokens < 0 {
+			wait = time.Duration(-b.tokens / b.rate * float64(time.Second))
+		}
+	}
+
+	if pause := b.pausedUntil.Sub(now); pause > wait {
+		wait = pause
+	}
+
+	return wait
+}
+
+// pause blocks the bucket until the given time
+func (b *tokenBucket) pause(until time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if until.After(b.pausedUntil) {
+		b.pausedUntil = until
+	}
+}
+
+// hostLimiter keeps one token bucket per host
+type hostLimiter struct {
+	mu      sync.Mutex
+	rate    float64
+	burst   int
+	buckets map[string]*tokenBucket
+}
+
+// newHostLimiter creates a limiter allowing rate requests per second per host
+func newHostLimiter(rate float64, burst int) *hostLimiter {
+	if burst <= 0 {
+		burst = 1
+	}
+
+	return &hostLimiter{
+		rate:    rate,
+		burst:   burst,
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// bucket returns the bucket of a host, creating it on first use
+func (l *hostLimiter) bucket(host string) *tokenBucket {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets[host]
+	if !ok {
+		b = &tokenBucket{
+			rate:   l.rate,
+			burst:  float64(l.burst),
+			tokens: float64(l.burst),
+			last:   time.Now(),
+		}
+		l.buckets[host] = b
+	}
+
+	return b
+}
+
+// Wait blocks until a request to host is allowed or ctx is done
+func (l *hostLimiter) Wait(ctx context.Context, host string) error {
+	wait := l.bucket(host).reserve(time.Now())
+	if wait <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Pause stops all requests to host for the given duration
+func (l *hostLimiter) Pause(host string, d time.Duration) {
+	l.bucket(host).pause(time.Now().Add(d))
+}
@@ -0,0 +1,144 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+const (
+	// retryBaseDelay is the back-off ceiling of the first retry
+	retryBaseDelay = 500 * time.Millisecond
+
+	// retryMaxDelay caps the back-off ceiling of later retries
+	retryMaxDelay = 30 * time.Second
+
+	// maxDrainBytes is how much of a discarded body is read so the
+	// connection can be reused; larger bodies are closed unread
+	maxDrainBytes = 64 << 10
+)
+
+// errBodyNotReplayable is returned when a request with a body has to be
+// retried but cannot recreate its body
+var errBodyNotReplayable = errors.New("request body cannot be replayed, GetBody is not set")
+
+// RetryError is returned by RequestWithRetry when no attempt succeeded
+type RetryError struct {
+	Method     string
+	URL        string
+	Attempts   int
+	StatusCode int // status of the last response, 0 if none was received
+	Err        error
+}
+
+// Error implements the error interface
+func (e *RetryError) Error() string {
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("%s %s failed after %d attempt(s) with status %d: %v", e.Method, e.URL, e.Attempts, e.StatusCode, e.Err)
+	}
+	return fmt.Sprintf("%s %s failed after %d attempt(s): %v", e.Method, e.URL, e.Attempts, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+// retryableError reports whether a transport error is worth another
+// attempt: timeouts, reset or refused connections and truncated responses
+func retryableError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, ErrCassetteMiss) {
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF)
+}
+
+// retryableStatus reports whether a response status is worth another attempt
+func retryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryDelay returns a random delay up to an exponentially growing
+// ceiling ("full jitter"), which keeps concurrent clients from retrying in
+// lockstep
+func retryDelay(attempt int) time.Duration {
+	ceiling := retryBaseDelay
+	for i := 0; i < attempt && ceiling < retryMaxDelay; i++ {
+		ceiling *= 2
+	}
+	ceiling = min(ceiling, retryMaxDelay)
+
+	return rand.N(ceiling + 1)
+}
+
+// attemptRequest returns the request to send on an attempt. Retries get a
+// fresh copy with its body recreated through GetBody.
+func attemptRequest(ctx context.Context, req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 0 {
+		return req.WithContext(ctx), nil
+	}
+
+	r := req.Clone(ctx)
+	if req.Body == nil || req.Body == http.NoBody {
+		return r, nil
+	}
+	if req.GetBody == nil {
+		return nil, errBodyNotReplayable
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf("failed to recreate request body: %w", err)
+	}
+	r.Body = body
+
+	return r, nil
+}
+
+// drainBody discards the rest of a response body and closes it, letting the
+// transport reuse the connection
+func drainBody(resp *http.Response) {
+	io.CopyN(io.Discard, resp.Body, maxDrainBytes)
+	resp.Body.Close()
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,118 @@
+package pipeline
+
+import (
+	"runtime"
+
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/common/goroutine"
+)
+
+const (
+	// defaultBuffer is the capacity of the output channel of a stage
+	defaultBuffer = 16
+
+	// defaultQueue is the number of items a prioritized stage reads ahead
+	// of its workers
+	defaultQueue = 256
+)
+
+// Option represents configuration options for a stage
+type Option func(*Config)
+
+// Config holds configuration for a stage
+type Config struct {
+	Name        string
+	Concurrency int
+	Buffer      int
+	Ordered     bool
+	OnError     func(err error) error
+	PoolOptions []goroutine.WorkerPoolOption
+	Queue       int
+}
+
+// WithName names the stage. Map stages report their worker pool statistics
+// under this name, see goroutine.AllStats.
+func WithName(name string) Option {
+	return func(c *Config) {
+		c.Name = name
+	}
+}
+
+// WithConcurrency sets the number of goroutines processing items of the stage
+func WithConcurrency(n int) Option {
+	return func(c *Config) {
+		c.Concurrency = n
+	}
+}
+
+// WithBuffer sets how many processed items may wait for the next stage
+// before the stage blocks
+func WithBuffer(n int) Option {
+	return func(c *Config) {
+		c.Buffer = n
+	}
+}
+
+// WithOrdered sets whether a Map stage emits items in input order
+func WithOrdered(ordered bool) Option {
+	return func(c *Config) {
+		c.Ordered = ordered
+	}
+}
+
+// WithErrorHandler sets the handler of item errors. Returning nil drops the
+// item and keeps the pipeline running; returning an error fails the
+// pipeline. Without a handler every error is fatal.
+func WithErrorHandler(handler func(err error) error) Option {
+	return func(c *Config) {
+		c.OnError = handler
+	}
+}
+
+// WithPoolOptions passes options such as timeouts and retry policies to
+// the worker pool of a Map stage
+func WithPoolOptions(opts ...goroutine.WorkerPoolOption) Option {
+	return func(c *Config) {
+		c.PoolOptions = append(c.PoolOptions, opts...)
+	}
+}
+
+// WithQueue sets how many items a MapByPriority stage reads ahead of its
+// workers to rank them. The stage blocks its input once the queue is full.
+func WithQueue(n int) Option {
+	return func(c *Config) {
+		c.Queue = n
+	}
+}
+
+// newConfig returns the stage configuration with defaults applied
+func newConfig(concurrency int, opts []Option) *Config {
+	config := &Config{
+		Concurrency: concurrency,
+		Buffer:      defaultBuffer,
+		Queue:       defaultQueue,
+	}
+
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	if config.Concurrency <= 0 {
+		config.Concurrency = runtime.GOMAXPROCS(0)
+	}
+	if config.Buffer < 0 {
+		config.Buffer = 0
+	}
+	if config.Queue <= 0 {
+		config.Queue = defaultQueue
+	}
+
+	return config
+}
+
+// handle passes an item error through the error handler
+func (c *Config) handle(err error) error {
+	if c.OnError == nil {
+		return err
+	}
+	return c.OnError(err)
+}
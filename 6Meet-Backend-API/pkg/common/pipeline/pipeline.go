@@ -0,0 +1,275 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/common/goroutine"
+)
+
+// ErrNotResizable is returned when resizing a stage without a worker pool
+var ErrNotResizable = errors.New("stage has no worker pool to resize")
+
+// Pipeline connects a source, processing stages and sinks with bounded
+// channels. A slow stage blocks the ones before it, and the first fatal
+// error cancels every stage.
+type Pipeline struct {
+	parent context.Context
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	once sync.Once
+	err  error
+}
+
+// New creates a pipeline whose stages stop when ctx is done
+func New(ctx context.Context) *Pipeline {
+	pctx, cancel := context.WithCancel(ctx)
+
+	return &Pipeline{
+		parent: ctx,
+		ctx:    pctx,
+		cancel: cancel,
+	}
+}
+
+// Context returns the context shared by the stages, cancelled on the first
+// fatal error
+func (p *Pipeline) Context() context.Context {
+	return p.ctx
+}
+
+// fail records the first fatal error and cancels every stage
+func (p *Pipeline) fail(err error) {
+	p.once.Do(func() {
+		p.err = err
+		p.cancel()
+	})
+}
+
+// Wait blocks until every stage has finished and returns the first fatal
+// error, or the error of the parent context if it was cancelled
+func (p *Pipeline) Wait() error {
+	p.wg.Wait()
+	p.cancel()
+
+	if p.err != nil {
+		return p.err
+	}
+	return p.parent.Err()
+}
+
+// goStage runs a stage goroutine tracked by Wait
+func (p *Pipeline) goStage(fn func()) {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		fn()
+	}()
+}
+
+// pool is the worker pool behind a Map stage
+type pool interface {
+	Resize(n int) error
+	Stats() goroutine.Stats
+}
+
+// Stage is the output of a pipeline stage
+type Stage[T any] struct {
+	out  <-chan T
+	pool pool
+}
+
+// Resize changes the concurrency of a Map stage while it runs
+func (s *Stage[T]) Resize(n int) error {
+	if s.pool == nil {
+		return ErrNotResizable
+	}
+	return s.pool.Resize(n)
+}
+
+// Stats returns the worker pool statistics of a Map stage, nil for other stages
+func (s *Stage[T]) Stats() *goroutine.Stats {
+	if s.pool == nil {
+		return nil
+	}
+	stats := s.pool.Stats()
+	return &stats
+}
+
+// From starts a source stage. fn produces items with emit, which returns an
+// error once the pipeline is cancelled; fn should then return.
+func From[T any](p *Pipeline, fn func(ctx context.Context, emit func(T) error) error, opts ...Option) *Stage[T] {
+	config := newConfig(1, opts)
+	out := make(chan T, config.Buffer)
+
+	emit := func(item T) error {
+		select {
+		case out <- item:
+			return nil
+		case <-p.ctx.Done():
+			return p.ctx.Err()
+		}
+	}
+
+	p.goStage(func() {
+		defer close(out)
+
+		if err := fn(p.ctx, emit); err != nil && p.ctx.Err() == nil {
+			if err = config.handle(err); err != nil {
+				p.fail(err)
+			}
+		}
+	})
+
+	return &Stage[T]{out: out}
+}
+
+// FromSlice starts a source stage emitting the items of a slice
+func FromSlice[T any](p *Pipeline, items []T, opts ...Option) *Stage[T] {
+	return From(p, func(ctx context.Context, emit func(T) error) error {
+		for _, item := range items {
+			if err := emit(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, opts...)
+}
+
+// mapTask adapts an item and the stage function to a worker pool task
+type mapTask[In, Out any] struct {
+	item In
+	fn   func(ctx context.Context, item In) (Out, error)
+}
+
+// TaskID identifies the task by its item
+func (t *mapTask[In, Out]) TaskID() string {
+	if id, ok := any(t.item).(goroutine.Identifier); ok {
+		return id.TaskID()
+	}
+	return fmt.Sprint(t.item)
+}
+
+// Process implements the Task interface for WorkerPool
+func (t *mapTask[In, Out]) Process(ctx context.Context) (Out, error) {
+	return t.fn(ctx, t.item)
+}
+
+// Map starts a stage that transforms every item of in with fn on a worker
+// pool. Item errors are goroutine.TaskError values carrying the item
+// identity and are passed to the error handler.
+func Map[In, Out any](p *Pipeline, in *Stage[In], fn func(ctx context.Context, item In) (Out, error), opts ...Option) *Stage[Out] {
+	return mapStage(p, in, fn, nil, opts)
+}
+
+// MapByPriority starts a Map stage that hands items to its workers by
+// priority, highest first, instead of in input order. Up to the queue size
+// set by WithQueue items wait to be ranked; beyond that the stage blocks
+// its input like Map does.
+func MapByPriority[In, Out any](p *Pipeline, in *Stage[In], fn func(ctx context.Context, item In) (Out, error), priority func(item In) int, opts ...Option) *Stage[Out] {
+	return mapStage(p, in, fn, priority, opts)
+}
+
+// mapStage starts a Map stage, prioritized when priority is not nil
+func mapStage[In, Out any](p *Pipeline, in *Stage[In], fn func(ctx context.Context, item In) (Out, error), priority func(item In) int, opts []Option) *Stage[Out] {
+	config := newConfig(0, opts)
+	out := make(chan Out, config.Buffer)
+
+	poolOpts := []goroutine.WorkerPoolOption{
+		goroutine.WithMaxWorkers(config.Concurrency),
+		goroutine.WithName(config.Name),
+		goroutine.WithOutcomes(true),
+		goroutine.WithOrderedResults(config.Ordered),
+	}
+	wp := goroutine.NewWorkerPool[Out](p.ctx, append(poolOpts, config.PoolOptions...)...)
+	wp.Start()
+
+	// A prioritized item holds a slot until its outcome is forwarded, so
+	// the pool queue stays bounded while the workers are busy
+	var slots chan struct{}
+	if priority != nil {
+		slots = make(chan struct{}, config.Concurrency+config.Queue)
+	}
+
+	// Feed the input into the pool; Submit blocks while every worker is busy,
+	// prioritized items wait in the pool queue until it is full instead
+	p.goStage(func() {
+		defer wp.Shutdown(context.Background())
+
+		for item := range in.out {
+			task := &mapTask[In, Out]{item: item, fn: fn}
+
+			var err error
+			if priority != nil {
+				select {
+				case slots <- struct{}{}:
+					err = wp.SubmitWithPriority(task, priority(item))
+				case <-p.ctx.Done():
+					err = p.ctx.Err()
+				}
+			} else {
+				err = wp.Submit(task)
+			}
+			if err != nil {
+				if p.ctx.Err() == nil {
+					p.fail(err)
+				}
+				break
+			}
+		}
+
+		// Let the upstream stage finish once the pipeline is cancelled
+		for range in.out {
+		}
+	})
+
+	// Forward the outcomes, blocking the pool while the next stage is busy
+	p.goStage(func() {
+		defer close(out)
+
+		for outcome := range wp.Outcomes() {
+			if outcome.Err != nil {
+				if err := config.handle(outcome.Err); err != nil {
+					p.fail(err)
+				}
+			} else {
+				select {
+				case out <- outcome.Value:
+				case <-p.ctx.Done():
+				}
+			}
+
+			if slots != nil {
+				<-slots
+			}
+		}
+	})
+
+	return &Stage[Out]{out: out, pool: wp}
+}
+
+// Sink starts the final stage, calling fn for every item of in. Without
+// WithConcurrency items are handled one at a time, so fn needs no locking.
+func Sink[T any](p *Pipeline, in *Stage[T], fn func(ctx context.Context, item T) error, opts ...Option) {
+	config := newConfig(1, opts)
+
+	for i := 0; i < config.Concurrency; i++ {
+		p.goStage(func() {
+			for item := range in.out {
+				if p.ctx.Err() != nil {
+					continue
+				}
+
+				if err := fn(p.ctx, item); err != nil {
+					if err = config.handle(err); err != nil {
+						p.fail(err)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/common/goroutine"
+)
+
+func TestPipelineMapAndSink(t *testing.T) {
+	p := New(context.Background())
+
+	numbers := FromSlice(p, []int{1, 2, 3, 4, 5})
+	squares := Map(p, numbers, func(ctx context.Context, n int) (int, error) {
+		return n * n, nil
+	}, WithConcurrency(3), WithOrdered(true))
+
+	var got []int
+	Sink(p, squares, func(ctx context.Context, n int) error {
+		got = append(got, n)
+		return nil
+	})
+
+	if err := p.Wait(); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if want := []int{1, 4, 9, 16, 25}; !slices.Equal(got, want) {
+		t.Errorf("results = %v; want %v", got, want)
+	}
+}
+
+func TestPipelineErrorHandlerDropsItems(t *testing.T) {
+	p := New(context.Background())
+
+	var handled atomic.Int32
+	numbers := FromSlice(p, []int{1, 2, 3, 4})
+	evens := Map(p, numbers, func(ctx context.Context, n int) (int, error) {
+		if n%2 == 1 {
+			return 0, errors.New("odd")
+		}
+		return n, nil
+	}, WithErrorHandler(func(err error) error {
+		var taskErr *goroutine.TaskError
+		if !errors.As(err, &taskErr) {
+			t.Errorf("item error = %T; want a TaskError", err)
+		}
+		handled.Add(1)
+		return nil
+	}), WithOrdered(true))
+
+	var got []int
+	Sink(p, evens, func(ctx context.Context, n int) error {
+		got = append(got, n)
+		return nil
+	})
+
+	if err := p.Wait(); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if want := []int{2, 4}; !slices.Equal(got, want) {
+		t.Errorf("results = %v; want %v", got, want)
+	}
+	if handled.Load() != 2 {
+		t.Errorf("handled %d errors; want 2", handled.Load())
+	}
+}
+
+func TestPipelineFatalErrorCancelsStages(t *testing.T) {
+	errFatal := errors.New("fatal")
+	p := New(context.Background())
+
+	// An endless source must stop once the sink fails
+	numbers := From(p, func(ctx context.Context, emit func(int) error) error {
+		for i := 0; ; i++ {
+			if err := emit(i); err != nil {
+				return err
+			}
+		}
+	})
+	doubled := Map(p, numbers, func(ctx context.Context, n int) (int, error) {
+		return 2 * n, nil
+	})
+	Sink(p, doubled, func(ctx context.Context, n int) error {
+		if n >= 10 {
+			return errFatal
+		}
+		return nil
+	})
+
+	done := make(chan error)
+	go func() { done <- p.Wait() }()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, errFatal) {
+			t.Errorf("Wait() error = %v; want errFatal", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("pipeline did not stop after a fatal error")
+	}
+}
+
+func TestPipelineParentCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := New(ctx)
+
+	numbers := From(p, func(ctx context.Context, emit func(int) error) error {
+		for i := 0; ; i++ {
+			if err := emit(i); err != nil {
+				return err
+			}
+		}
+	})
+	Sink(p, numbers, func(ctx context.Context, n int) error {
+		if n == 5 {
+			cancel()
+		}
+		return nil
+	})
+
+	if err := p.Wait(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait() error = %v; want context.Canceled", err)
+	}
+}
+
+func TestMapByPriority(t *testing.T) {
+	p := New(context.Background())
+
+	items := FromSlice(p, []int{3, 8, 1, 6, 2, 7, 4, 5})
+
+	// The single worker is held by the first item until every other item is
+	// queued, except the one the pool dispatcher already took for it
+	release := make(chan struct{})
+	var started atomic.Bool
+	ranked := MapByPriority(p, items, func(ctx context.Context, n int) (int, error) {
+		if started.CompareAndSwap(false, true) {
+			<-release
+		}
+		return n, nil
+	}, func(n int) int {
+		return n
+	}, WithConcurrency(1), WithOrdered(false))
+
+	go func() {
+		for ranked.Stats().Queued < 6 {
+			time.Sleep(time.Millisecond)
+		}
+		close(release)
+	}()
+
+	var got []int
+	Sink(p, ranked, func(ctx context.Context, n int) error {
+		got = append(got, n)
+		return nil
+	})
+
+	if err := p.Wait(); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if len(got) != 8 || !slices.IsSortedFunc(got[2:], func(a, b int) int { return b - a }) {
+		t.Errorf("processing order = %v; want the queued items highest first", got)
+	}
+}
+
+func TestMapByPriorityBoundsQueue(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := New(ctx)
+
+	var emitted atomic.Int32
+	items := From(p, func(ctx context.Context, emit func(int) error) error {
+		for i := 0; ; i++ {
+			if err := emit(i); err != nil {
+				return err
+			}
+			emitted.Add(1)
+		}
+	}, WithBuffer(0))
+
+	release := make(chan struct{})
+	ranked := MapByPriority(p, items, func(ctx context.Context, n int) (int, error) {
+		select {
+		case <-release:
+		case <-ctx.Done():
+		}
+		return n, nil
+	}, func(n int) int {
+		return n
+	}, WithConcurrency(1), WithQueue(2), WithBuffer(0))
+	Sink(p, ranked, func(ctx context.Context, n int) error { return nil })
+
+	// One item per slot, one in the hand of the feeder and one emitted
+	// into the unbuffered source
+	time.Sleep(20 * time.Millisecond)
+	if n := emitted.Load(); n > 5 {
+		t.Errorf("source emitted %d items while the worker was busy; want at most 5", n)
+	}
+
+	cancel()
+	close(release)
+	p.Wait()
+}
+
+func TestStageResize(t *testing.T) {
+	p := New(context.Background())
+
+	source := FromSlice(p, []int{1})
+	if err := source.Resize(2); !errors.Is(err, ErrNotResizable) {
+		t.Errorf("Resize() of a source error = %v; want ErrNotResizable", err)
+	}
+	if source.Stats() != nil {
+		t.Error("Stats() of a source is not nil")
+	}
+
+	mapped := Map(p, source, func(ctx context.Context, n int) (int, error) { return n, nil }, WithConcurrency(1))
+	if err := mapped.Resize(3); err != nil {
+		t.Errorf("Resize() of a Map stage error = %v", err)
+	}
+	Sink(p, mapped, func(ctx context.Context, n int) error { return nil })
+
+	if err := p.Wait(); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+}
@@ -0,0 +1,167 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrNotExecuted is reported for the operations of an ordered bulk write
+// that were skipped because an earlier operation failed
+var ErrNotExecuted = errors.New("operation not executed after an earlier failure")
+
+// BulkResult is the outcome of one operation of a bulk write
+type BulkResult struct {
+	UpsertedID primitive.ObjectID // set when an upsert inserted a new document
+	Err        error              // nil when the operation succeeded
+}
+
+// BulkWrite executes the write models in a single round-trip and returns one
+// result per model. Ordered writes stop at the first failure and report the
+// remaining models as ErrNotExecuted; unordered writes attempt every model.
+// The error is only set when the write as a whole failed.
+func (r *BaseRepository[T]) BulkWrite(ctx context.Context, models []mongo.WriteModel, ordered bool) ([]BulkResult, error) {
+	if len(models) == 0 {
+		return nil, nil
+	}
+
+	res, err := r.collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(ordered))
+
+	var bulkErr mongo.BulkWriteException
+	if err != nil && !errors.As(err, &bulkErr) {
+		return nil, err
+	}
+	if bulkErr.WriteConcernError != nil {
+		return nil, bulkErr
+	}
+
+	results := make([]BulkResult, len(models))
+	if res != nil {
+		for index, id := range res.UpsertedIDs {
+			if oid, ok := id.(primitive.ObjectID); ok {
+				results[index].UpsertedID = oid
+			}
+		}
+	}
+
+	first := len(models)
+	for _, writeErr := range bulkErr.WriteErrors {
+		if writeErr.Index >= 0 && writeErr.Index < len(models) {
+			results[writeErr.Index].Err = writeErr
+			first = min(first, writeErr.Index)
+		}
+	}
+	if ordered {
+		for i := first + 1; i < len(models); i++ {
+			results[i].Err = ErrNotExecuted
+		}
+	}
+
+	return results, nil
+}
+
+// CreateMany inserts the documents in a single unordered round-trip.
+// Documents without an ID get one before they are sent.
+func (r *BaseRepository[T]) CreateMany(ctx context.Context, models []*T) ([]BulkResult, error) {
+	writes := make([]mongo.WriteModel, len(models))
+	for i, model := range models {
+		if (*model).GetID().IsZero() {
+			(*model).SetID(primitive.NewObjectID())
+		}
+		writes[i] = mongo.NewInsertOneModel().SetDocument(model)
+	}
+
+	return r.BulkWrite(ctx, writes, false)
+}
+
+// UpsertMany updates the live documents whose key field matches the one of
+// each model and inserts the others, in a single unordered round-trip. The ID and
+// creation time of existing documents are kept and their version is bumped
+// without being checked; models that were inserted get their new ID.
+func (r *BaseRepository[T]) UpsertMany(ctx context.Context, key string, models []*T) ([]BulkResult, error) {
+	results := make([]BulkResult, len(models))
+
+	var (
+		writes  []mongo.WriteModel
+		indexes []int // position in models of each write
+	)
+	for i, model := range models {
+		write, err := upsertModel(key, model)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		writes = append(writes, write)
+		indexes = append(indexes, i)
+	}
+
+	written, err := r.BulkWrite(ctx, writes, false)
+	if err != nil {
+		return nil, err
+	}
+
+	for j, result := range written {
+		i := indexes[j]
+		results[i] = result
+		if !result.UpsertedID.IsZero() {
+			(*models[i]).SetID(result.UpsertedID)
+		}
+	}
+
+	return results, nil
+}
+
+// upsertModel builds the upsert of a model keyed by one of its fields
+func upsertModel[T Document](key string, model *T) (mongo.WriteModel, error) {
+	(*model).UpdateTimestamp()
+
+	data, err := bson.Marshal(model)
+	if err != nil {
+		return nil, err
+	}
+	var doc bson.M
+	if err := bson.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	id := (*model).GetID()
+	if id.IsZero() {
+		id = primitive.NewObjectID()
+	}
+	createdAt, ok := doc["created_at"].(primitive.DateTime)
+	if !ok || createdAt.Time().IsZero() {
+		createdAt = primitive.NewDateTimeFromTime(time.Now())
+	}
+	delete(doc, "_id")
+	delete(doc, "created_at")
+	delete(doc, "version")
+
+	onInsert := bson.M{"created_at": createdAt}
+
+	// Soft-deleted documents are never updated: a match by key creates a new
+	// document, and a match by the _id of a deleted one fails as a duplicate
+	var filter bson.M
+	if key == "_id" {
+		filter = live(bson.M{"_id": id})
+	} else {
+		value, ok := doc[key]
+		if !ok {
+			return nil, fmt.Errorf("document has no %q field to upsert by", key)
+		}
+		filter = live(bson.M{key: value})
+		onInsert["_id"] = id
+	}
+
+	update := bson.M{"$set": doc, "$setOnInsert": onInsert, "$inc": bson.M{"version": 1}}
+
+	return mongo.NewUpdateOneModel().
+		SetFilter(filter).
+		SetUpdate(update).
+		SetUpsert(true), nil
+}
@@ -0,0 +1,57 @@
+package mongodb
+
+import (
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// namedModel is a document with a name to upsert by
+type namedModel struct {
+	*BaseModel `bson:",inline"`
+	Name       string `bson:"name"`
+}
+
+func TestUpsertModelSkipsDeletedDocuments(t *testing.T) {
+	id := primitive.NewObjectID()
+
+	tests := []struct {
+		name  string
+		key   string
+		model *namedModel
+		want  bson.M
+	}{
+		{
+			name:  "by name",
+			key:   "name",
+			model: &namedModel{BaseModel: &BaseModel{}, Name: "Go"},
+			want:  bson.M{"name": "Go", fieldDeletedAt: nil},
+		},
+		{
+			name:  "by id",
+			key:   "_id",
+			model: &namedModel{BaseModel: &BaseModel{ID: id}, Name: "Go"},
+			want:  bson.M{"_id": id, fieldDeletedAt: nil},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			write, err := upsertModel(tt.key, &tt.model)
+			if err != nil {
+				t.Fatalf("upsertModel() error = %v", err)
+			}
+
+			update := write.(*mongo.UpdateOneModel)
+			if !reflect.DeepEqual(update.Filter, tt.want) {
+				t.Errorf("filter = %v; want %v", update.Filter, tt.want)
+			}
+			if update.Upsert == nil || !*update.Upsert {
+				t.Error("write is not an upsert")
+			}
+		})
+	}
+}
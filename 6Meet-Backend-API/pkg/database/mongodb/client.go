@@ -25,6 +25,7 @@ type MongoDBClient interface {
 	connect() error
 	setDefaultConfig()
 	buildURI() string
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
 	Close() error
 }
 
@@ -0,0 +1,144 @@
+package mongodb
+
+import (
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/dto"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// pageCursor is the position of a page boundary in a sort order. It holds
+// the sort values of the boundary document, ending with its _id.
+type pageCursor struct {
+	Sort   []string `bson:"s"` // sort keys, prefixed with "-" when descending
+	Values bson.A   `bson:"v"`
+	Before bool     `bson:"b"` // page ends before the position instead of starting after it
+}
+
+// sortSignature describes a sort order so cursors can be checked against it
+func sortSignature(sort bson.D) []string {
+	keys := make([]string, len(sort))
+	for i, e := range sort {
+		keys[i] = e.Key
+		if e.Value == -1 {
+			keys[i] = "-" + e.Key
+		}
+	}
+	return keys
+}
+
+// encodeCursor returns the opaque cursor of a document in a sort order
+func encodeCursor(sort bson.D, doc bson.Raw, before bool) (string, error) {
+	values := make(bson.A, len(sort))
+	for i, e := range sort {
+		value, err := doc.LookupErr(strings.Split(e.Key, ".")...)
+		if err != nil || value.Type == bson.TypeNull || value.Type == bson.TypeUndefined {
+			values[i] = nil // missing and null sort alike
+			continue
+		}
+		values[i] = value
+	}
+
+	data, err := bson.Marshal(pageCursor{
+		Sort:   sortSignature(sort),
+		Values: values,
+		Before: before,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeCursor parses a cursor issued for the same sort order
+func decodeCursor(sort bson.D, cursor string) (*pageCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", dto.ErrInvalidCursor, err)
+	}
+
+	var c pageCursor
+	if err := bson.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("%w: %v", dto.ErrInvalidCursor, err)
+	}
+	if !slices.Equal(c.Sort, sortSignature(sort)) || len(c.Values) != len(sort) {
+		return nil, fmt.Errorf("%w: issued for another sort order", dto.ErrInvalidCursor)
+	}
+
+	// Cursors are not signed, so a forged document value must not reach
+	// the query as an operator
+	for _, value := range c.Values {
+		if !isCursorValue(value) {
+			return nil, fmt.Errorf("%w: unsupported value %T", dto.ErrInvalidCursor, value)
+		}
+	}
+
+	return &c, nil
+}
+
+// isCursorValue reports whether a decoded sort value is a plain scalar
+func isCursorValue(value any) bool {
+	switch value.(type) {
+	case nil, string, bool, int32, int64, float64,
+		primitive.Decimal128, primitive.ObjectID, primitive.DateTime:
+		return true
+	default:
+		return false
+	}
+}
+
+// filter matches the documents after the cursor in the sort order, or
+// before it for a backward cursor: for keys k1..kn with values v1..vn, any
+// document equal on k1..ki-1 and past vi on ki.
+//
+// Missing and null values sort before every other value, but comparison
+// operators never match them, so null boundaries get their own branches:
+// past null ascending is any non-null value, nothing sorts below null, and
+// below a non-null value also includes the null values.
+func (c *pageCursor) filter(sort bson.D) bson.M {
+	branches := make(bson.A, 0, len(sort))
+	for i, e := range sort {
+		descending := (e.Value == -1) != c.Before
+		value := c.Values[i]
+		if value == nil && descending {
+			continue
+		}
+
+		branch := bson.M{}
+		for j := 0; j < i; j++ {
+			branch[sort[j].Key] = c.Values[j]
+		}
+		switch {
+		case value == nil:
+			branch[e.Key] = bson.M{"$ne": nil}
+		case descending:
+			branch["$or"] = bson.A{
+				bson.M{e.Key: bson.M{"$lt": value}},
+				bson.M{e.Key: nil},
+			}
+		default:
+			branch[e.Key] = bson.M{"$gt": value}
+		}
+		branches = append(branches, branch)
+	}
+
+	if len(branches) == 0 {
+		// Nothing sorts past the cursor
+		return bson.M{"_id": bson.M{"$exists": false}}
+	}
+	return bson.M{"$or": branches}
+}
+
+// invertSort reverses every key of a sort order
+func invertSort(sort bson.D) bson.D {
+	inverted := make(bson.D, len(sort))
+	for i, e := range sort {
+		inverted[i] = bson.E{Key: e.Key, Value: -e.Value.(int)}
+	}
+	return inverted
+}
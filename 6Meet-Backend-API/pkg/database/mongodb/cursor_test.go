@@ -0,0 +1,185 @@
+package mongodb
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/dto"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	sort := bson.D{{Key: "neighbor_count", Value: -1}, {Key: "_id", Value: -1}}
+	doc, err := bson.Marshal(bson.D{{Key: "_id", Value: "u1"}, {Key: "neighbor_count", Value: int32(7)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := encodeCursor(sort, doc, true)
+	if err != nil {
+		t.Fatalf("encodeCursor() error = %v", err)
+	}
+
+	c, err := decodeCursor(sort, encoded)
+	if err != nil {
+		t.Fatalf("decodeCursor() error = %v", err)
+	}
+	if !c.Before || !reflect.DeepEqual(c.Values, bson.A{int32(7), "u1"}) {
+		t.Errorf("cursor = %+v; want values [7 u1] before", c)
+	}
+
+	other := bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}
+	if _, err := decodeCursor(other, encoded); !errors.Is(err, dto.ErrInvalidCursor) {
+		t.Errorf("decodeCursor() for another sort error = %v; want ErrInvalidCursor", err)
+	}
+	if _, err := decodeCursor(sort, "not a cursor"); !errors.Is(err, dto.ErrInvalidCursor) {
+		t.Errorf("decodeCursor() of garbage error = %v; want ErrInvalidCursor", err)
+	}
+}
+
+func TestCursorNullValues(t *testing.T) {
+	sort := bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}}
+	for _, doc := range []bson.D{
+		{{Key: "_id", Value: "u1"}},
+		{{Key: "_id", Value: "u1"}, {Key: "name", Value: nil}},
+	} {
+		raw, err := bson.Marshal(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		encoded, err := encodeCursor(sort, raw, false)
+		if err != nil {
+			t.Fatalf("encodeCursor() error = %v", err)
+		}
+		c, err := decodeCursor(sort, encoded)
+		if err != nil {
+			t.Fatalf("decodeCursor() error = %v", err)
+		}
+		if c.Values[0] != nil {
+			t.Errorf("boundary name of %v = %#v; want nil", doc, c.Values[0])
+		}
+	}
+}
+
+func TestCursorFilter(t *testing.T) {
+	asc := bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}}
+	desc := bson.D{{Key: "name", Value: -1}, {Key: "_id", Value: -1}}
+
+	tests := []struct {
+		name   string
+		sort   bson.D
+		values bson.A
+		before bool
+		want   bson.M
+	}{
+		{
+			name:   "ascending",
+			sort:   asc,
+			values: bson.A{"b", "u1"},
+			want: bson.M{"$or": bson.A{
+				bson.M{"name": bson.M{"$gt": "b"}},
+				bson.M{"name": "b", "_id": bson.M{"$gt": "u1"}},
+			}},
+		},
+		{
+			name:   "descending includes nulls",
+			sort:   desc,
+			values: bson.A{"b", "u1"},
+			want: bson.M{"$or": bson.A{
+				bson.M{"$or": bson.A{bson.M{"name": bson.M{"$lt": "b"}}, bson.M{"name": nil}}},
+				bson.M{"name": "b", "$or": bson.A{bson.M{"_id": bson.M{"$lt": "u1"}}, bson.M{"_id": nil}}},
+			}},
+		},
+		{
+			name:   "ascending past null",
+			sort:   asc,
+			values: bson.A{nil, "u1"},
+			want: bson.M{"$or": bson.A{
+				bson.M{"name": bson.M{"$ne": nil}},
+				bson.M{"name": nil, "_id": bson.M{"$gt": "u1"}},
+			}},
+		},
+		{
+			name:   "descending past null",
+			sort:   desc,
+			values: bson.A{nil, "u1"},
+			want: bson.M{"$or": bson.A{
+				bson.M{"name": nil, "$or": bson.A{bson.M{"_id": bson.M{"$lt": "u1"}}, bson.M{"_id": nil}}},
+			}},
+		},
+		{
+			name:   "backward ascending past null",
+			sort:   asc,
+			values: bson.A{nil, "u1"},
+			before: true,
+			want: bson.M{"$or": bson.A{
+				bson.M{"name": nil, "$or": bson.A{bson.M{"_id": bson.M{"$lt": "u1"}}, bson.M{"_id": nil}}},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &pageCursor{Sort: sortSignature(tt.sort), Values: tt.values, Before: tt.before}
+			if got := c.filter(tt.sort); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filter() = %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateSortUsesSortFields(t *testing.T) {
+	repo := NewBaseRepository[*BaseModel](nil,
+		WithQueryFields("name", "aliases"),
+		WithSortFields("name"),
+	)
+
+	if err := ValidateSort(&[]dto.SortOption{{Key: "name"}}, repo.sortable); err != nil {
+		t.Errorf("sort by name error = %v", err)
+	}
+	if err := ValidateSort(&[]dto.SortOption{{Key: "aliases"}}, repo.sortable); !errors.Is(err, dto.ErrInvalidFilter) {
+		t.Errorf("sort by an array field error = %v; want ErrInvalidFilter", err)
+	}
+
+	fallback := NewBaseRepository[*BaseModel](nil, WithQueryFields("name"))
+	if !fallback.sortable["name"] {
+		t.Error("sort fields do not default to the query fields")
+	}
+}
+
+func TestDecodeCursorRejectsForgedValues(t *testing.T) {
+	sort := bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}}
+
+	forge := func(values bson.A) string {
+		data, err := bson.Marshal(pageCursor{Sort: sortSignature(sort), Values: values})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+
+	tests := []struct {
+		name   string
+		values bson.A
+	}{
+		{name: "operator document", values: bson.A{bson.M{"$ne": nil}, "u1"}},
+		{name: "regex document", values: bson.A{"a", bson.D{{Key: "$regex", Value: ".*"}}}},
+		{name: "array", values: bson.A{bson.A{"a"}, "u1"}},
+		{name: "regex", values: bson.A{primitive.Regex{Pattern: ".*"}, "u1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeCursor(sort, forge(tt.values)); !errors.Is(err, dto.ErrInvalidCursor) {
+				t.Errorf("decodeCursor() error = %v; want ErrInvalidCursor", err)
+			}
+		})
+	}
+
+	if _, err := decodeCursor(sort, forge(bson.A{nil, primitive.NewObjectID()})); err != nil {
+		t.Errorf("decodeCursor() of scalar values error = %v", err)
+	}
+}
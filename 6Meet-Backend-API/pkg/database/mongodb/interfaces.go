@@ -2,11 +2,13 @@ package mongodb
 
 import (
 	"context"
+	"time"
 
 	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/dto"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type mongoRepository struct {
@@ -23,6 +25,8 @@ func NewMongoRepository(db *mongo.Client) *mongoRepository {
 type Document interface {
 	GetID() primitive.ObjectID
 	SetID(primitive.ObjectID)
+	GetVersion() int64
+	SetVersion(int64)
 	UpdateTimestamp()
 }
 
@@ -30,10 +34,20 @@ type Document interface {
 type Repository[T Document] interface {
     Find(ctx context.Context, opts *dto.QueryOptions) (*dto.Paginated[T], error)
     Get(ctx context.Context, id primitive.ObjectID) (*T, error)
+    FindOne(ctx context.Context, filter bson.M) (*T, error)
+    FindAll(ctx context.Context, filter bson.M, opts ...*options.FindOptions) ([]T, error)
 
     Create(ctx context.Context, model *T) error
+    CreateMany(ctx context.Context, models []*T) ([]BulkResult, error)
+    UpsertMany(ctx context.Context, key string, models []*T) ([]BulkResult, error)
+    BulkWrite(ctx context.Context, models []mongo.WriteModel, ordered bool) ([]BulkResult, error)
     Update(ctx context.Context, id primitive.ObjectID, model *T) error
+    Touch(ctx context.Context, ids []primitive.ObjectID) (int64, error)
     Delete(ctx context.Context, id primitive.ObjectID) error
+    DeleteVersion(ctx context.Context, id primitive.ObjectID, version int64) error
+    Restore(ctx context.Context, id primitive.ObjectID) error
+    Purge(ctx context.Context, id primitive.ObjectID) error
+    PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
     DeleteMany(ctx context.Context, filter bson.M) (int64, error)
 
     Exists(ctx context.Context, id primitive.ObjectID) (bool, error)
@@ -0,0 +1,313 @@
+package mongodb
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// migrationCollection records the applied migrations
+	migrationCollection = "schema_migrations"
+
+	// migrationLockCollection holds the lock taken while migrating
+	migrationLockCollection = "schema_migrations_lock"
+	migrationLockID         = "lock"
+
+	// migrationLockTTL is how long a lock outlives a migrator that stopped
+	// renewing it, e.g. because its process died
+	migrationLockTTL = time.Minute
+
+	// migrationLockPoll is how often a waiting migrator retries the lock
+	migrationLockPoll = time.Second
+)
+
+// MigrateFunc applies or reverts one migration
+type MigrateFunc func(ctx context.Context, db *mongo.Database) error
+
+// Migration is a versioned schema change. Down may be nil for migrations
+// that cannot be undone, e.g. data merges; reverting them only forgets they
+// were applied.
+type Migration struct {
+	Version     int64
+	Description string
+	Up          MigrateFunc
+	Down        MigrateFunc
+}
+
+// MigrationStatus is a migration and whether it has been applied
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// migrationRecord is the document stored for an applied migration
+type migrationRecord struct {
+	Version     int64     `bson:"_id"`
+	Description string    `bson:"description"`
+	AppliedAt   time.Time `bson:"applied_at"`
+}
+
+// Migrator applies migrations in version order and records them in the
+// schema_migrations collection. Up and Down hold a lock, so concurrent
+// migrators, e.g. several instances starting at once, run one at a time.
+type Migrator struct {
+	db         *mongo.Database
+	collection *mongo.Collection
+	locks      *mongo.Collection
+	migrations []Migration
+}
+
+// NewMigrator creates a migrator for the given migrations
+func NewMigrator(db *mongo.Database, migrations ...Migration) (*Migrator, error) {
+	migrations = slices.Clone(migrations)
+	slices.SortFunc(migrations, func(a, b Migration) int {
+		return cmp.Compare(a.Version, b.Version)
+	})
+
+	for i, migration := range migrations {
+		if migration.Up == nil {
+			return nil, fmt.Errorf("migration %d has no up step", migration.Version)
+		}
+		if i > 0 && migrations[i-1].Version == migration.Version {
+			return nil, fmt.Errorf("duplicate migration version %d", migration.Version)
+		}
+	}
+
+	return &Migrator{
+		db:         db,
+		collection: db.Collection(migrationCollection),
+		locks:      db.Collection(migrationLockCollection),
+		migrations: migrations,
+	}, nil
+}
+
+// Status lists every migration with the time it was applied
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, len(m.migrations))
+	for i, migration := range m.migrations {
+		statuses[i].Migration = migration
+		if record, ok := applied[migration.Version]; ok {
+			statuses[i].AppliedAt = &record.AppliedAt
+		}
+	}
+
+	return statuses, nil
+}
+
+// Up applies the pending migrations in version order and returns them.
+// It waits for the migration lock and stops at the first failing migration.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	unlock, err := m.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		if err := migration.Up(ctx, m.db); err != nil {
+			return done, fmt.Errorf("migration %d (%s) failed: %w", migration.Version, migration.Description, err)
+		}
+
+		record := migrationRecord{
+			Version:     migration.Version,
+			Description: migration.Description,
+			AppliedAt:   time.Now(),
+		}
+		if _, err := m.collection.InsertOne(ctx, record); err != nil {
+			return done, fmt.Errorf("failed to record migration %d: %w", migration.Version, err)
+		}
+
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// Down reverts the last steps applied migrations, newest first, and
+// returns them. It waits for the migration lock.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	unlock, err := m.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+
+		if migration.Down != nil {
+			if err := migration.Down(ctx, m.db); err != nil {
+				return done, fmt.Errorf("reverting migration %d (%s) failed: %w", migration.Version, migration.Description, err)
+			}
+		}
+
+		if _, err := m.collection.DeleteOne(ctx, bson.M{"_id": migration.Version}); err != nil {
+			return done, fmt.Errorf("failed to unrecord migration %d: %w", migration.Version, err)
+		}
+
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// applied returns the recorded migrations by version
+func (m *Migrator) applied(ctx context.Context) (map[int64]migrationRecord, error) {
+	cursor, err := m.collection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var records []migrationRecord
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int64]migrationRecord, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+
+	return applied, nil
+}
+
+// lock waits until the migration lock is taken and renews it until the
+// returned unlock function releases it
+func (m *Migrator) lock(ctx context.Context) (func(), error) {
+	owner := primitive.NewObjectID()
+
+	for {
+		locked, err := m.tryLock(ctx, owner)
+		if err != nil {
+			return nil, fmt.Errorf("failed to take the migration lock: %w", err)
+		}
+		if locked {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("waiting for the migration lock: %w", ctx.Err())
+		case <-time.After(migrationLockPoll):
+		}
+	}
+
+	stop := make(chan struct{})
+	renewed := make(chan struct{})
+	go func() {
+		defer close(renewed)
+
+		ticker := time.NewTicker(migrationLockTTL / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				// A failed renewal is retried on the next tick, well before the lock expires
+				_, _ = m.locks.UpdateOne(ctx,
+					bson.M{"_id": migrationLockID, "owner": owner},
+					bson.M{"$set": bson.M{"expires_at": time.Now().Add(migrationLockTTL)}},
+				)
+			}
+		}
+	}()
+
+	unlock := func() {
+		close(stop)
+		<-renewed
+		// An unreleased lock expires on its own
+		_, _ = m.locks.DeleteOne(context.WithoutCancel(ctx), bson.M{"_id": migrationLockID, "owner": owner})
+	}
+
+	return unlock, nil
+}
+
+// tryLock takes the migration lock if it is free or expired. An upsert
+// against a lock held by another migrator fails on the duplicate _id.
+func (m *Migrator) tryLock(ctx context.Context, owner primitive.ObjectID) (bool, error) {
+	now := time.Now()
+	filter := bson.M{"_id": migrationLockID, "expires_at": bson.M{"$lt": now}}
+	update := bson.M{"$set": bson.M{"owner": owner, "expires_at": now.Add(migrationLockTTL)}}
+
+	_, err := m.locks.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// IndexMigration returns a migration creating the indexes of a collection
+// and dropping them when reverted. Every index must be named so it can be
+// dropped again.
+func IndexMigration(version int64, description, collection string, indexes []mongo.IndexModel) Migration {
+	return Migration{
+		Version:     version,
+		Description: description,
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection(collection).Indexes().CreateMany(ctx, indexes)
+			return err
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			for _, index := range indexes {
+				name, err := indexName(index)
+				if err != nil {
+					return err
+				}
+				if _, err := db.Collection(collection).Indexes().DropOne(ctx, name); err != nil && !isIndexNotFound(err) {
+					return fmt.Errorf("failed to drop index %s: %w", name, err)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// indexName returns the declared name of an index
+func indexName(index mongo.IndexModel) (string, error) {
+	if index.Options == nil || index.Options.Name == nil {
+		return "", errors.New("index declared without a name")
+	}
+	return *index.Options.Name, nil
+}
+
+// isIndexNotFound reports whether dropping an index failed because it does not exist
+func isIndexNotFound(err error) bool {
+	var cmdErr mongo.CommandError
+	return errors.As(err, &cmdErr) && cmdErr.Name == "IndexNotFound"
+}
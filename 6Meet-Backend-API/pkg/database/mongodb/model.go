@@ -11,6 +11,8 @@ type BaseModel struct {
 	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
 	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
 	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+	Version   int64              `bson:"version" json:"version"` // incremented by every update
+	DeletedAt *time.Time         `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
 }
 
 // NewBaseModel creates a new BaseModel with current timestamp
@@ -33,6 +35,16 @@ func (b *BaseModel) SetID(id primitive.ObjectID) {
 	b.ID = id
 }
 
+// GetVersion returns the Version field
+func (b *BaseModel) GetVersion() int64 {
+	return b.Version
+}
+
+// SetVersion sets the Version field
+func (b *BaseModel) SetVersion(version int64) {
+	b.Version = version
+}
+
 // UpdateTimestamp updates the UpdatedAt field
 func (b *BaseModel) UpdateTimestamp() {
 	b.UpdatedAt = time.Now()
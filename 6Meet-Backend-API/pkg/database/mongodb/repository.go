@@ -2,27 +2,80 @@ package mongodb
 
 import (
 	"context"
+	"errors"
+	"slices"
 	"time"
 
 	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/dto"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// fieldDeletedAt marks soft-deleted documents
+const fieldDeletedAt = "deleted_at"
+
+// ErrVersionConflict is returned by Update when the document was modified
+// after the model was read
+var ErrVersionConflict = errors.New("document was modified concurrently")
+
+// ErrNotDeleted is returned by Purge when the document was not soft-deleted
+var ErrNotDeleted = errors.New("document is not deleted")
+
 // BaseRepository provides common database operations using generics
 type BaseRepository[T Document] struct {
 	collection *mongo.Collection
 	timeout    time.Duration
+	fields     Fields // fields clients may filter and select in Find
+	sortable   Fields // fields clients may sort by in Find
 }
 
 var _ Repository[*BaseModel] = (*BaseRepository[*BaseModel])(nil)
 
+// RepositoryOption configures a BaseRepository
+type RepositoryOption func(*repositoryOptions)
+
+type repositoryOptions struct {
+	fields   Fields
+	sortable Fields
+}
+
+// WithQueryFields limits the fields clients may filter and sort by in Find.
+// Every field is allowed without it.
+func WithQueryFields(fields ...string) RepositoryOption {
+	return func(o *repositoryOptions) {
+		o.fields = NewFields(fields...)
+	}
+}
+
+// WithSortFields limits the fields clients may sort by in Find, overriding
+// WithQueryFields. Keyset pages need scalar sort keys: an array field sorts
+// by its smallest or largest element and cannot be paged through with a
+// cursor.
+func WithSortFields(fields ...string) RepositoryOption {
+	return func(o *repositoryOptions) {
+		o.sortable = NewFields(fields...)
+	}
+}
+
 // NewBaseRepository creates a new base repository
-func NewBaseRepository[T Document](collection *mongo.Collection) *BaseRepository[T] {
+func NewBaseRepository[T Document](collection *mongo.Collection, opts ...RepositoryOption) *BaseRepository[T] {
+	var o repositoryOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	sortable := o.sortable
+	if sortable == nil {
+		sortable = o.fields
+	}
+
 	return &BaseRepository[T]{
 		collection: collection,
 		timeout:    30 * time.Second,
+		fields:     o.fields,
+		sortable:   sortable,
 	}
 }
 
@@ -36,11 +89,39 @@ func (r *BaseRepository[T]) GetCollection() *mongo.Collection {
 	return r.collection
 }
 
+// live excludes soft-deleted documents from a filter, unless the filter
+// asks about deleted_at itself
+func live(filter bson.M) bson.M {
+	if _, ok := filter[fieldDeletedAt]; ok {
+		return filter
+	}
+
+	f := make(bson.M, len(filter)+1)
+	for k, v := range filter {
+		f[k] = v
+	}
+	f[fieldDeletedAt] = nil // matches missing and null
+
+	return f
+}
+
 // Get retrieves a document by ID
 func (r *BaseRepository[T]) Get(ctx context.Context, id primitive.ObjectID) (*T, error) {
 	var model T
 
-	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&model)
+	err := r.collection.FindOne(ctx, live(bson.M{"_id": id})).Decode(&model)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model, nil
+}
+
+// FindOne retrieves the first document matching the filter
+func (r *BaseRepository[T]) FindOne(ctx context.Context, filter bson.M) (*T, error) {
+	var model T
+
+	err := r.collection.FindOne(ctx, live(filter)).Decode(&model)
 	if err != nil {
 		return nil, err
 	}
@@ -48,6 +129,22 @@ func (r *BaseRepository[T]) Get(ctx context.Context, id primitive.ObjectID) (*T,
 	return &model, nil
 }
 
+// FindAll retrieves every document matching the filter
+func (r *BaseRepository[T]) FindAll(ctx context.Context, filter bson.M, opts ...*options.FindOptions) ([]T, error) {
+	cursor, err := r.collection.Find(ctx, live(filter), opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var records []T
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
 // Create inserts a new document
 func (r *BaseRepository[T]) Create(ctx context.Context, model *T) error {
 	res, err := r.collection.InsertOne(ctx, model)
@@ -62,22 +159,157 @@ func (r *BaseRepository[T]) Create(ctx context.Context, model *T) error {
 	return nil
 }
 
-// Update updates a document by ID
+// Update updates a document by ID if it still has the version of the
+// model, then bumps the version of the model. It fails with
+// ErrVersionConflict when the document was changed since the model was
+// read and with mongo.ErrNoDocuments when it does not exist.
 func (r *BaseRepository[T]) Update(ctx context.Context, id primitive.ObjectID, model *T) error {
+	version := (*model).GetVersion()
+
 	(*model).UpdateTimestamp()
+	(*model).SetVersion(version + 1)
 
 	update := bson.M{"$set": model}
-	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
-	return err
+	res, err := r.collection.UpdateOne(ctx, versionFilter(id, version), update)
+	if err != nil {
+		(*model).SetVersion(version)
+		return err
+	}
+	if res.MatchedCount > 0 {
+		return nil
+	}
+
+	(*model).SetVersion(version)
+	exists, err := r.Exists(ctx, id)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrVersionConflict
+	}
+	return mongo.ErrNoDocuments
+}
+
+// versionFilter matches a live document at a version. Documents written
+// before versioning have no version field and count as version 0.
+func versionFilter(id primitive.ObjectID, version int64) bson.M {
+	if version == 0 {
+		return live(bson.M{"_id": id, "$or": bson.A{
+			bson.M{"version": 0},
+			bson.M{"version": bson.M{"$exists": false}},
+		}})
+	}
+	return live(bson.M{"_id": id, "version": version})
+}
+
+// Touch sets the update time of the given live documents without changing
+// them or their version, and returns how many were matched
+func (r *BaseRepository[T]) Touch(ctx context.Context, ids []primitive.ObjectID) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	update := bson.M{"$set": bson.M{"updated_at": time.Now()}}
+	res, err := r.collection.UpdateMany(ctx, live(bson.M{"_id": bson.M{"$in": ids}}), update)
+	if err != nil {
+		return 0, err
+	}
+	return res.MatchedCount, nil
 }
 
-// Delete removes a document by ID
+// Delete soft-deletes a document by ID: it stays in the collection, hidden
+// from every read, until it is restored or purged
 func (r *BaseRepository[T]) Delete(ctx context.Context, id primitive.ObjectID) error {
-	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
-	return err
+	return r.softDelete(ctx, live(bson.M{"_id": id}))
+}
+
+// DeleteVersion soft-deletes a document by ID if it still has the given
+// version. It fails with ErrVersionConflict when the document was changed
+// since and with mongo.ErrNoDocuments when it does not exist.
+func (r *BaseRepository[T]) DeleteVersion(ctx context.Context, id primitive.ObjectID, version int64) error {
+	err := r.softDelete(ctx, versionFilter(id, version))
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return err
+	}
+
+	exists, err := r.Exists(ctx, id)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrVersionConflict
+	}
+	return mongo.ErrNoDocuments
 }
 
-// DeleteMany removes multiple documents based on filter
+// softDelete marks the document matched by filter as deleted
+func (r *BaseRepository[T]) softDelete(ctx context.Context, filter bson.M) error {
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{fieldDeletedAt: now, "updated_at": now},
+		"$inc": bson.M{"version": 1},
+	}
+
+	res, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// Restore brings back a soft-deleted document by ID
+func (r *BaseRepository[T]) Restore(ctx context.Context, id primitive.ObjectID) error {
+	update := bson.M{
+		"$unset": bson.M{fieldDeletedAt: ""},
+		"$set":   bson.M{"updated_at": time.Now()},
+		"$inc":   bson.M{"version": 1},
+	}
+
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": id, fieldDeletedAt: bson.M{"$ne": nil}}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// Purge permanently removes a soft-deleted document by ID. It fails with
+// ErrNotDeleted when the document is still live.
+func (r *BaseRepository[T]) Purge(ctx context.Context, id primitive.ObjectID) error {
+	res, err := r.collection.DeleteOne(ctx, bson.M{"_id": id, fieldDeletedAt: bson.M{"$ne": nil}})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount > 0 {
+		return nil
+	}
+
+	exists, err := r.Exists(ctx, id)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrNotDeleted
+	}
+	return mongo.ErrNoDocuments
+}
+
+// PurgeDeleted permanently removes the documents soft-deleted before the
+// given time
+func (r *BaseRepository[T]) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.collection.DeleteMany(ctx, bson.M{fieldDeletedAt: bson.M{"$lt": before}})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// DeleteMany permanently removes multiple documents based on filter
 func (r *BaseRepository[T]) DeleteMany(ctx context.Context, filter bson.M) (int64, error) {
 	result, err := r.collection.DeleteMany(ctx, filter)
 	if err != nil {
@@ -88,14 +320,16 @@ func (r *BaseRepository[T]) DeleteMany(ctx context.Context, filter bson.M) (int6
 
 // Exists checks whether a document exists by its ID
 func (r *BaseRepository[T]) Exists(ctx context.Context, id primitive.ObjectID) (bool, error) {
-	count, err := r.collection.CountDocuments(ctx, bson.M{"_id": id})
+	count, err := r.collection.CountDocuments(ctx, live(bson.M{"_id": id}))
 	if err != nil {
 		return false, err
 	}
 	return count > 0, nil
 }
 
-// Find retrieves documents with pagination, search/filter, and sorting
+// Find retrieves documents with pagination, search/filter, and sorting.
+// Pages are addressed by page number, or by the opaque cursors returned in
+// the pagination meta, which stay stable while documents are inserted.
 func (r *BaseRepository[T]) Find(ctx context.Context, opts *dto.QueryOptions) (*dto.Paginated[T], error) {
 	if opts == nil {
 		opts = &dto.QueryOptions{}
@@ -104,45 +338,109 @@ func (r *BaseRepository[T]) Find(ctx context.Context, opts *dto.QueryOptions) (*
 		opts.Pagination = &dto.PaginationOptions{}
 	}
 	opts.Pagination.SetDefaults()
+	page := opts.Pagination
 
 	// Build filter from search/filter options
-	filter := BuildFilter(&opts.Filters)
+	filter, err := BuildFilter(&opts.Filters, r.fields)
+	if err != nil {
+		return nil, err
+	}
+	filter = live(filter)
 
-	// Cursor pagination: if cursor is present, filter by _id < cursor (assuming desc sort)
-	if opts.Pagination.Cursor != "" {
-		if oid, err := primitive.ObjectIDFromHex(opts.Pagination.Cursor); err == nil {
-			filter["_id"] = bson.M{"$lt": oid}
+	// Build sort from sort options
+	if err := ValidateSort(&opts.Sort, r.sortable); err != nil {
+		return nil, err
+	}
+	sort := BuildSort(&opts.Sort)
+
+	// Cursor pagination: continue after (or before) the position of the cursor
+	query, querySort := filter, sort
+	var cursor *pageCursor
+	if page.Cursor != "" {
+		if cursor, err = decodeCursor(sort, page.Cursor); err != nil {
+			return nil, err
+		}
+		query = bson.M{"$and": bson.A{filter, cursor.filter(sort)}}
+		if cursor.Before {
+			querySort = invertSort(sort)
 		}
 	}
 
-	// Count total documents
-	totalItems, err := r.collection.CountDocuments(ctx, filter)
+	// Only return the requested fields
+	projection, err := BuildProjection(opts.Fields, sort, r.fields)
 	if err != nil {
 		return nil, err
 	}
 
-	// Calculate pagination info
-	pagination := dto.CalculatePagination(opts.Pagination.Page, opts.Pagination.PageSize, totalItems)
-
-	// Build sort from sort options
-	sort := BuildSort(&opts.Sort)
-
 	// Find documents with pagination and sorting
-	findOpts := GetPaginationOptions(opts.Pagination)
-	findOpts.SetSort(sort)
+	findOpts := GetPaginationOptions(page)
+	findOpts.SetSort(querySort)
+	if projection != nil {
+		findOpts.SetProjection(projection)
+	}
 
-	cursor, err := r.collection.Find(ctx, filter, findOpts)
+	res, err := r.collection.Find(ctx, query, findOpts)
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(ctx)
+	defer res.Close(ctx)
 
-	// Decode documents
-	var records []T
-	if err := cursor.All(ctx, &records); err != nil {
+	var docs []bson.Raw
+	if err := res.All(ctx, &docs); err != nil {
 		return nil, err
 	}
 
+	more := len(docs) > page.PageSize
+	if more {
+		docs = docs[:page.PageSize]
+	}
+	if cursor != nil && cursor.Before {
+		slices.Reverse(docs)
+	}
+
+	// Decode documents
+	records := make([]T, len(docs))
+	for i, doc := range docs {
+		if err := bson.Unmarshal(doc, &records[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	// Calculate pagination info
+	pagination := &dto.PaginationMeta{
+		CurrentPage: page.Page,
+		PageSize:    page.PageSize,
+	}
+	if !page.SkipCount {
+		totalItems, err := r.collection.CountDocuments(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		pagination = dto.CalculatePagination(page.Page, page.PageSize, totalItems)
+	}
+
+	switch {
+	case cursor == nil:
+		pagination.HasNext, pagination.HasPrev = more, page.Page > 1
+	case cursor.Before:
+		pagination.HasNext, pagination.HasPrev = true, more
+	default:
+		pagination.HasNext, pagination.HasPrev = more, true
+	}
+
+	if len(docs) > 0 {
+		if pagination.HasNext {
+			if pagination.NextCursor, err = encodeCursor(sort, docs[len(docs)-1], false); err != nil {
+				return nil, err
+			}
+		}
+		if pagination.HasPrev {
+			if pagination.PrevCursor, err = encodeCursor(sort, docs[0], true); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	return &dto.Paginated[T]{
 		Records:    &records,
 		Pagination: pagination,
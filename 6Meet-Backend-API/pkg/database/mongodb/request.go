@@ -1,15 +1,23 @@
 package mongodb
 
 import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
 	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/dto"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-// GetPaginationOptions creates MongoDB options for pagination
+// GetPaginationOptions creates MongoDB options for pagination. One document
+// more than the page size is fetched to tell whether another page follows.
 func GetPaginationOptions(p *dto.PaginationOptions) *options.FindOptions {
-	limit := int64(p.PageSize)
+	limit := int64(p.PageSize) + 1
 
 	findOptions := &options.FindOptions{
 		Limit: &limit,
@@ -24,72 +32,263 @@ func GetPaginationOptions(p *dto.PaginationOptions) *options.FindOptions {
 	return findOptions
 }
 
-// BuildFilter creates MongoDB filter from SearchFilter slice
-func BuildFilter(filters *[]dto.SearchFilter) bson.M {
-	filter := bson.M{}
-	
+// maxFilterDepth bounds the nesting of and/or groups
+const maxFilterDepth = 4
+
+// Fields is the set of fields clients may filter and sort by. A nil set
+// allows every field; operator-like names starting with "$" are never allowed.
+type Fields map[string]bool
+
+// NewFields creates a field set
+func NewFields(names ...string) Fields {
+	fields := make(Fields, len(names))
+	for _, name := range names {
+		fields[name] = true
+	}
+	return fields
+}
+
+// allows reports whether clients may use a field
+func (f Fields) allows(key string) bool {
+	if strings.HasPrefix(key, "$") {
+		return false
+	}
+	return f == nil || f[key]
+}
+
+// BuildFilter creates MongoDB filter from SearchFilter slice. Filters on
+// fields outside the allowed set or with malformed values fail with
+// dto.ErrInvalidFilter.
+func BuildFilter(filters *[]dto.SearchFilter, fields Fields) (bson.M, error) {
 	if filters == nil {
-		return filter
+		return bson.M{}, nil
+	}
+
+	conditions, err := buildConditions(*filters, fields, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(conditions) {
+	case 0:
+		return bson.M{}, nil
+	case 1:
+		return conditions[0].(bson.M), nil
+	default:
+		return bson.M{"$and": conditions}, nil
+	}
+}
+
+// buildConditions creates one condition per filter, skipping empty filters
+func buildConditions(filters []dto.SearchFilter, fields Fields, depth int) (bson.A, error) {
+	conditions := bson.A{}
+	for i := range filters {
+		condition, err := buildCondition(&filters[i], fields, depth)
+		if err != nil {
+			return nil, err
+		}
+		if condition != nil {
+			conditions = append(conditions, condition)
+		}
 	}
 
-	for i := range *filters {
-		f := &(*filters)[i]
-		if f.Key == "" || f.Value == nil {
-			continue
+	return conditions, nil
+}
+
+// buildCondition creates the condition of a single filter
+func buildCondition(f *dto.SearchFilter, fields Fields, depth int) (bson.M, error) {
+	if f.Type == dto.FilterAnd || f.Type == dto.FilterOr {
+		if depth >= maxFilterDepth {
+			return nil, fmt.Errorf("%w: groups nested deeper than %d", dto.ErrInvalidFilter, maxFilterDepth)
+		}
+
+		conditions, err := buildConditions(f.Filters, fields, depth+1)
+		if err != nil || len(conditions) == 0 {
+			return nil, err
 		}
+		return bson.M{"$" + f.Type: conditions}, nil
+	}
+
+	if f.Key == "" || f.Value == nil {
+		return nil, nil
+	}
+	if !fields.allows(f.Key) {
+		return nil, fmt.Errorf("%w: cannot filter by %q", dto.ErrInvalidFilter, f.Key)
+	}
 
-		switch f.Type {
-		case "search":
-			// Text search using regex
-			if str, ok := f.Value.(string); ok && str != "" {
-				filter[f.Key] = bson.M{"$regex": str, "$options": "i"}
+	invalid := func(expected string) error {
+		return fmt.Errorf("%w: %s filter on %q needs %s", dto.ErrInvalidFilter, cmp.Or(f.Type, dto.FilterExact), f.Key, expected)
+	}
+
+	switch f.Type {
+	case dto.FilterSearch, dto.FilterRegex:
+		// Text search using regex
+		str, ok := f.Value.(string)
+		if !ok {
+			return nil, invalid("a string")
+		}
+		if str == "" {
+			return nil, nil
+		}
+		if f.Type == dto.FilterSearch {
+			return bson.M{f.Key: bson.M{"$regex": str, "$options": "i"}}, nil
+		}
+		return bson.M{f.Key: bson.M{"$regex": str}}, nil
+	case dto.FilterID:
+		if str, ok := f.Value.(string); ok {
+			// Convert string ID to ObjectID
+			if objectID, err := primitive.ObjectIDFromHex(str); err == nil {
+				return bson.M{f.Key: bson.M{"$in": []primitive.ObjectID{objectID}}}, nil
 			}
-		case "exact":
-			filter[f.Key] = f.Value
-		case "filter":
-			if str, ok := f.Value.(string); ok {
-				// Convert string ID to ObjectID
-				if objectID, err := primitive.ObjectIDFromHex(str); err == nil {
-					filter[f.Key] = bson.M{"$in": []primitive.ObjectID{objectID}}
-				}
-			} else {
-				filter[f.Key] = f.Value
+			return nil, nil
+		}
+		if !isScalar(f.Value) {
+			return nil, invalid("a scalar value")
+		}
+		return bson.M{f.Key: f.Value}, nil
+	case dto.FilterGt, dto.FilterGte, dto.FilterLt, dto.FilterLte:
+		if !isScalar(f.Value) {
+			return nil, invalid("a scalar value")
+		}
+		return bson.M{f.Key: bson.M{"$" + f.Type: rangeValue(f.Key, f.Value)}}, nil
+	case dto.FilterIn, dto.FilterNin:
+		values, ok := f.Value.([]interface{})
+		if !ok {
+			return nil, invalid("an array")
+		}
+		converted := make(bson.A, len(values))
+		for i, value := range values {
+			if !isScalar(value) {
+				return nil, invalid("an array of scalar values")
 			}
-		default:
-			// Default to exact match
-			filter[f.Key] = f.Value
+			converted[i] = filterValue(f.Key, value)
+		}
+		return bson.M{f.Key: bson.M{"$" + f.Type: converted}}, nil
+	case dto.FilterExists:
+		exists, ok := f.Value.(bool)
+		if !ok {
+			return nil, invalid("a boolean")
+		}
+		return bson.M{f.Key: bson.M{"$exists": exists}}, nil
+	case dto.FilterSize:
+		size, ok := f.Value.(float64)
+		if !ok || size < 0 || size != math.Trunc(size) {
+			return nil, invalid("a non-negative integer")
 		}
+		return bson.M{f.Key: bson.M{"$size": int64(size)}}, nil
+	case dto.FilterExact, "":
+		if !isScalar(f.Value) {
+			return nil, invalid("a scalar value")
+		}
+		return bson.M{f.Key: filterValue(f.Key, f.Value)}, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown filter type %q", dto.ErrInvalidFilter, f.Type)
 	}
+}
 
-	return filter
+// isScalar reports whether a decoded JSON value is a plain value, so
+// objects cannot smuggle query operators into a filter
+func isScalar(value interface{}) bool {
+	switch value.(type) {
+	case string, float64, bool, int, int64:
+		return true
+	default:
+		return false
+	}
 }
 
-// BuildSort creates MongoDB sort from SortOption slice
-func BuildSort(sorts *[]dto.SortOption) bson.M {
-	sort := bson.M{}
+// filterValue converts hex strings compared with _id to ObjectIDs
+func filterValue(key string, value interface{}) interface{} {
+	if str, ok := value.(string); ok && key == "_id" {
+		if objectID, err := primitive.ObjectIDFromHex(str); err == nil {
+			return objectID
+		}
+	}
+	return value
+}
 
+// rangeValue converts RFC 3339 strings to times so date fields can be
+// compared by range
+func rangeValue(key string, value interface{}) interface{} {
+	if str, ok := value.(string); ok {
+		if t, err := time.Parse(time.RFC3339, str); err == nil {
+			return t
+		}
+	}
+	return filterValue(key, value)
+}
+
+// BuildProjection creates MongoDB projection returning only the given
+// fields. Sort keys are always returned since page cursors are built from
+// them. A nil projection returns every field.
+func BuildProjection(fields []string, sort bson.D, allowed Fields) (bson.D, error) {
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	projection := bson.D{}
+	include := func(key string) {
+		if !slices.ContainsFunc(projection, func(e bson.E) bool { return e.Key == key }) {
+			projection = append(projection, bson.E{Key: key, Value: 1})
+		}
+	}
+
+	for _, field := range fields {
+		if !allowed.allows(field) {
+			return nil, fmt.Errorf("%w: cannot select %q", dto.ErrInvalidFilter, field)
+		}
+		include(field)
+	}
+	for _, e := range sort {
+		include(e.Key)
+	}
+
+	return projection, nil
+}
+
+// ValidateSort checks that every sort key is in the allowed set
+func ValidateSort(sorts *[]dto.SortOption, fields Fields) error {
 	if sorts == nil {
-		// Default sort if no sort specified
-		sort["created_at"] = -1
-		return sort
+		return nil
 	}
 
-	for i := range *sorts {
-		s := &(*sorts)[i]
-		if s.Key == "" {
-			continue
+	for _, s := range *sorts {
+		if s.Key != "" && !fields.allows(s.Key) {
+			return fmt.Errorf("%w: cannot sort by %q", dto.ErrInvalidFilter, s.Key)
 		}
+	}
 
-		order := s.Order
-		if order != 1 && order != -1 {
-			order = -1 // Default to descending
+	return nil
+}
+
+// BuildSort creates MongoDB sort from SortOption slice. The keys keep their
+// order and end with _id so every document has a distinct position, which
+// keyset pagination relies on.
+func BuildSort(sorts *[]dto.SortOption) bson.D {
+	sort := bson.D{}
+
+	if sorts != nil {
+		for i := range *sorts {
+			s := &(*sorts)[i]
+			if s.Key == "" || slices.ContainsFunc(sort, func(e bson.E) bool { return e.Key == s.Key }) {
+				continue
+			}
+
+			order := s.Order
+			if order != 1 && order != -1 {
+				order = -1 // Default to descending
+			}
+			sort = append(sort, bson.E{Key: s.Key, Value: order})
 		}
-		sort[s.Key] = order
 	}
 
 	// Default sort if no valid sort keys found
 	if len(sort) == 0 {
-		sort["created_at"] = -1
+		sort = append(sort, bson.E{Key: "created_at", Value: -1})
+	}
+
+	if !slices.ContainsFunc(sort, func(e bson.E) bool { return e.Key == "_id" }) {
+		sort = append(sort, bson.E{Key: "_id", Value: sort[len(sort)-1].Value})
 	}
 
 	return sort
@@ -0,0 +1,176 @@
+package mongodb
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/huynhanx03/6Meet/6Meet-Backend-API/pkg/dto"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestBuildFilter(t *testing.T) {
+	id := primitive.NewObjectID()
+	since := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		filters []dto.SearchFilter
+		want    bson.M
+	}{
+		{name: "none", want: bson.M{}},
+		{
+			name:    "exact",
+			filters: []dto.SearchFilter{{Key: "name", Value: "Go"}},
+			want:    bson.M{"name": "Go"},
+		},
+		{
+			name:    "exact _id",
+			filters: []dto.SearchFilter{{Key: "_id", Value: id.Hex()}},
+			want:    bson.M{"_id": id},
+		},
+		{
+			name:    "search",
+			filters: []dto.SearchFilter{{Key: "name", Value: "go", Type: dto.FilterSearch}},
+			want:    bson.M{"name": bson.M{"$regex": "go", "$options": "i"}},
+		},
+		{
+			name:    "empty search skipped",
+			filters: []dto.SearchFilter{{Key: "name", Value: "", Type: dto.FilterSearch}},
+			want:    bson.M{},
+		},
+		{
+			name:    "date range",
+			filters: []dto.SearchFilter{{Key: "created_at", Value: since.Format(time.RFC3339), Type: dto.FilterGte}},
+			want:    bson.M{"created_at": bson.M{"$gte": since}},
+		},
+		{
+			name:    "in",
+			filters: []dto.SearchFilter{{Key: "name", Value: []interface{}{"a", "b"}, Type: dto.FilterIn}},
+			want:    bson.M{"name": bson.M{"$in": bson.A{"a", "b"}}},
+		},
+		{
+			name:    "exists",
+			filters: []dto.SearchFilter{{Key: "aliases", Value: false, Type: dto.FilterExists}},
+			want:    bson.M{"aliases": bson.M{"$exists": false}},
+		},
+		{
+			name:    "size",
+			filters: []dto.SearchFilter{{Key: "neighbors", Value: float64(3), Type: dto.FilterSize}},
+			want:    bson.M{"neighbors": bson.M{"$size": int64(3)}},
+		},
+		{
+			name: "several",
+			filters: []dto.SearchFilter{
+				{Key: "name", Value: "Go"},
+				{Key: "neighbor_count", Value: float64(2), Type: dto.FilterGt},
+			},
+			want: bson.M{"$and": bson.A{
+				bson.M{"name": "Go"},
+				bson.M{"neighbor_count": bson.M{"$gt": float64(2)}},
+			}},
+		},
+		{
+			name: "or group",
+			filters: []dto.SearchFilter{{Type: dto.FilterOr, Filters: []dto.SearchFilter{
+				{Key: "name", Value: "Go"},
+				{Key: "aliases", Value: "Golang"},
+			}}},
+			want: bson.M{"$or": bson.A{bson.M{"name": "Go"}, bson.M{"aliases": "Golang"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BuildFilter(&tt.filters, nil)
+			if err != nil {
+				t.Fatalf("BuildFilter() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BuildFilter() = %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildFilterRejects(t *testing.T) {
+	nested := dto.SearchFilter{Key: "name", Value: "Go"}
+	for i := 0; i <= maxFilterDepth; i++ {
+		nested = dto.SearchFilter{Type: dto.FilterAnd, Filters: []dto.SearchFilter{nested}}
+	}
+
+	tests := []struct {
+		name   string
+		filter dto.SearchFilter
+	}{
+		{name: "field not allowed", filter: dto.SearchFilter{Key: "password", Value: "x"}},
+		{name: "operator field", filter: dto.SearchFilter{Key: "$where", Value: "x"}},
+		{name: "object value", filter: dto.SearchFilter{Key: "name", Value: map[string]interface{}{"$ne": ""}}},
+		{name: "regex on a number", filter: dto.SearchFilter{Key: "name", Value: float64(1), Type: dto.FilterRegex}},
+		{name: "in without an array", filter: dto.SearchFilter{Key: "name", Value: "Go", Type: dto.FilterIn}},
+		{name: "in with an object", filter: dto.SearchFilter{Key: "name", Value: []interface{}{map[string]interface{}{}}, Type: dto.FilterIn}},
+		{name: "fractional size", filter: dto.SearchFilter{Key: "aliases", Value: 1.5, Type: dto.FilterSize}},
+		{name: "unknown type", filter: dto.SearchFilter{Key: "name", Value: "Go", Type: "near"}},
+		{name: "nested too deep", filter: nested},
+	}
+
+	fields := NewFields("name", "aliases")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filters := []dto.SearchFilter{tt.filter}
+			if _, err := BuildFilter(&filters, fields); !errors.Is(err, dto.ErrInvalidFilter) {
+				t.Errorf("BuildFilter() error = %v; want ErrInvalidFilter", err)
+			}
+		})
+	}
+}
+
+func TestBuildSort(t *testing.T) {
+	tests := []struct {
+		name  string
+		sorts []dto.SortOption
+		want  bson.D
+	}{
+		{
+			name: "default newest first",
+			want: bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}},
+		},
+		{
+			name:  "tie-broken by _id",
+			sorts: []dto.SortOption{{Key: "name", Order: 1}},
+			want:  bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}},
+		},
+		{
+			name:  "duplicates dropped",
+			sorts: []dto.SortOption{{Key: "name", Order: -1}, {Key: "name", Order: 1}},
+			want:  bson.D{{Key: "name", Value: -1}, {Key: "_id", Value: -1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BuildSort(&tt.sorts); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BuildSort() = %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildProjectionIncludesSortKeys(t *testing.T) {
+	sort := bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}}
+
+	got, err := BuildProjection([]string{"aliases"}, sort, nil)
+	if err != nil {
+		t.Fatalf("BuildProjection() error = %v", err)
+	}
+	want := bson.D{{Key: "aliases", Value: 1}, {Key: "name", Value: 1}, {Key: "_id", Value: 1}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("BuildProjection() = %v; want %v", got, want)
+	}
+
+	if _, err := BuildProjection([]string{"password"}, sort, NewFields("name")); !errors.Is(err, dto.ErrInvalidFilter) {
+		t.Errorf("BuildProjection() of a hidden field error = %v; want ErrInvalidFilter", err)
+	}
+}
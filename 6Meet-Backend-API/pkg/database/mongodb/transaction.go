@@ -0,0 +1,32 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// WithTransaction runs fn in a transaction that is committed when fn
+// succeeds and aborted otherwise. Repository calls made with the ctx passed
+// to fn carry the session and join the transaction. The whole of fn is
+// retried on transient transaction errors and commits with an unknown
+// result are retried too, so fn must not have effects outside the database.
+// Calls made inside a transaction join it, and fn runs without a
+// transaction when transactions are disabled, e.g. on a standalone server.
+func (c *Client) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if !c.config.Transactions || mongo.SessionFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+
+	session, err := c.Client.StartSession()
+	if err != nil {
+		return fmt.Errorf("failed to start session: %w", err)
+	}
+	defer session.EndSession(context.WithoutCancel(ctx))
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sc)
+	})
+	return err
+}
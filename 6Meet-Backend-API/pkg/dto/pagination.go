@@ -1,12 +1,44 @@
 package dto
 
+import "errors"
+
+var (
+	// ErrInvalidCursor is returned for a pagination cursor that cannot be decoded
+	// or was issued for another sort order
+	ErrInvalidCursor = errors.New("invalid cursor")
+
+	// ErrInvalidFilter is returned for a filter or sort the repository does not accept
+	ErrInvalidFilter = errors.New("invalid filter")
+)
+
 // SearchFilter represents search and filter parameters
 type SearchFilter struct {
 	Key   string      `json:"key" form:"key"`     // Field name to search/filter
 	Value interface{} `json:"value" form:"value"` // Value to search/filter
-	Type  string      `json:"type" form:"type"`   // "search" or "filter" or "exact"
+	Type  string      `json:"type" form:"type"`   // One of the Filter* types, "exact" by default
+
+	// Filters are the conditions of an "and" or "or" group
+	Filters []SearchFilter `json:"filters,omitempty" form:"-"`
 }
 
+// Filter types of SearchFilter
+const (
+	FilterSearch = "search" // case-insensitive regular expression
+	FilterRegex  = "regex"  // case-sensitive regular expression
+	FilterExact  = "exact"  // equal to the value
+	FilterID     = "filter" // equal to the ObjectID of a hex string value
+	FilterGt     = "gt"
+	FilterGte    = "gte"
+	FilterLt     = "lt"
+	FilterLte    = "lte"
+	FilterIn     = "in"     // equal to one of the values of an array
+	FilterNin    = "nin"    // equal to none of the values of an array
+	FilterExists = "exists" // field present (true) or missing (false)
+	FilterSize   = "size"   // array field with exactly value elements
+	FilterAnd    = "and"    // every condition of Filters matches
+	FilterOr     = "or"     // any condition of Filters matches
+)
+
 // SortOption represents sorting parameters
 type SortOption struct {
 	Key   string `json:"key" form:"key"`     // Field name to sort by
@@ -18,6 +50,9 @@ type PaginationOptions struct {
 	Page     int    `json:"page" form:"page" binding:"min=1"`
 	PageSize int    `json:"page_size" form:"page_size" binding:"min=1,max=100"`
 	Cursor   string `json:"cursor" form:"cursor"` // Cursor for keyset pagination (optional)
+
+	// SkipCount leaves the totals out, which saves counting large collections
+	SkipCount bool `json:"skip_count" form:"skip_count"`
 }
 
 // QueryOptions combines pagination, search/filter, and sorting
@@ -25,6 +60,7 @@ type QueryOptions struct {
 	Pagination *PaginationOptions `json:"pagination"`
 	Filters    []SearchFilter     `json:"filters"`
 	Sort       []SortOption       `json:"sort"`
+	Fields     []string           `json:"fields"` // Fields returned for each record, all when empty
 }
 
 // PaginationMeta contains pagination information
@@ -35,6 +71,10 @@ type PaginationMeta struct {
 	TotalItems  int64 `json:"total_items"`
 	HasNext     bool  `json:"has_next"`
 	HasPrev     bool  `json:"has_prev"`
+
+	// Cursors of the next and previous pages, empty when there is none
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
 }
 
 // Paginated contains paginated data with pagination info
@@ -5,6 +5,8 @@ type Config struct {
 	MongoDB MongoDB `mapstructure:"mongodb"`
 	Logger  Logger  `mapstructure:"logger"`
 	Redis   Redis   `mapstructure:"redis"`
+	Crawler Crawler `mapstructure:"crawler"`
+	Purge   Purge   `mapstructure:"purge"`
 	// Kafka   Kafka   `mapstructure:"kafka"`
 }
 
@@ -13,6 +15,10 @@ type Server struct {
 	Mode string `mapstructure:"mode"`
 	Host string `mapstructure:"host"`
 	Port int    `mapstructure:"port"`
+
+	// AdminToken must be sent as X-Admin-Token to admin endpoints, which are
+	// disabled while it is empty
+	AdminToken string `mapstructure:"admin_token"`
 }
 
 // MongoDB is the configuration for MongoDB
@@ -26,6 +32,8 @@ type MongoDB struct {
 	MaxConnIdleTime uint64 `mapstructure:"max_conn_idle_time"`
 	Port            int    `mapstructure:"port"`
 	Timeout         int    `mapstructure:"timeout"`
+	Migrate         bool   `mapstructure:"migrate"`      // apply pending migrations at startup
+	Transactions    bool   `mapstructure:"transactions"` // use transactions, needs a replica set
 }
 
 // Logger is the configuration for the logger
@@ -54,3 +62,65 @@ type Redis struct {
 	MaxRetryBackoff int    `mapstructure:"max_retry_backoff"`
 	MinRetryBackoff int    `mapstructure:"min_retry_backoff"`
 }
+
+// Crawler is the configuration for the Wikipedia crawler
+type Crawler struct {
+	UserAgent     string   `mapstructure:"user_agent"`
+	RateLimit     float64  `mapstructure:"rate_limit"`      // requests per second per host
+	RateBurst     int      `mapstructure:"rate_burst"`      // requests allowed in a burst per host
+	MaxLag        int      `mapstructure:"max_lag"`         // seconds, MediaWiki maxlag parameter
+	MaxRetries    int      `mapstructure:"max_retries"`     // attempts per API request
+	MaxRetryAfter int      `mapstructure:"max_retry_after"` // seconds, upper bound for Retry-After
+	Cache         Cache    `mapstructure:"cache"`
+	Breaker       Breaker  `mapstructure:"breaker"`
+	Cassette      Cassette `mapstructure:"cassette"`
+	Refresh       Refresh  `mapstructure:"refresh"`
+	Jobs          Jobs     `mapstructure:"jobs"`
+}
+
+// Jobs is the configuration for the crawl job registry
+type Jobs struct {
+	Retention   int `mapstructure:"retention"`    // seconds a finished job is kept
+	MaxFinished int `mapstructure:"max_finished"` // finished jobs kept at most
+	MaxRunning  int `mapstructure:"max_running"`  // jobs allowed to run at once
+}
+
+// Cassette is the configuration for recording and replaying crawler HTTP traffic
+type Cassette struct {
+	Mode string `mapstructure:"mode"` // "record", "replay" or empty to use the network normally
+	Path string `mapstructure:"path"` // cassette file
+}
+
+// Breaker is the configuration for the crawler circuit breaker
+type Breaker struct {
+	FailureRatio   float64 `mapstructure:"failure_ratio"`    // failed share of requests that opens the breaker
+	MinRequests    int     `mapstructure:"min_requests"`     // requests in the window before the ratio is considered
+	Window         int     `mapstructure:"window"`           // seconds over which requests are counted
+	Cooldown       int     `mapstructure:"cooldown"`         // seconds the breaker stays open before probing
+	HalfOpenProbes int     `mapstructure:"half_open_probes"` // successful probes needed to close the breaker
+}
+
+// Cache is the configuration for the crawler HTTP response cache
+type Cache struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	TTL      int    `mapstructure:"ttl"`       // seconds a response is served before revalidation
+	MaxBytes int64  `mapstructure:"max_bytes"` // memory budget of the cache
+	Dir      string `mapstructure:"dir"`       // directory persisting responses, empty keeps them in memory only
+
+	DiskMaxBytes int64 `mapstructure:"disk_max_bytes"` // disk budget of the cache directory
+}
+
+// Refresh is the configuration for the stale page refresher
+type Refresh struct {
+	Enabled    bool `mapstructure:"enabled"`
+	Interval   int  `mapstructure:"interval"`    // seconds between refresh runs
+	StaleAfter int  `mapstructure:"stale_after"` // seconds after which a page is re-crawled
+	BatchSize  int  `mapstructure:"batch_size"`  // pages re-crawled per run
+}
+
+// Purge is the configuration for the purger of soft-deleted users
+type Purge struct {
+	Enabled   bool `mapstructure:"enabled"`
+	Interval  int  `mapstructure:"interval"`   // seconds between purge runs
+	RetainFor int  `mapstructure:"retain_for"` // seconds a deleted user can still be restored
+}
@@ -23,3 +23,7 @@ func HandleHitCache(ctx context.Context, model interface{}, c cache.CacheEngine,
 func HandleSetCache(ctx context.Context, model interface{}, c cache.CacheEngine, key string, ttl int) error {
 	return c.Set(ctx, key, model, ToDuration(ttl))
 }
+
+func HandleDeleteCache(ctx context.Context, c cache.CacheEngine, key string) error {
+	return c.Delete(ctx, key)
+}
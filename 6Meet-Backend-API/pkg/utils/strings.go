@@ -1,8 +1,25 @@
 package utils
 
-import "strings"
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
 
 // IsEmpty checks if a string is empty or contains only whitespace
 func IsEmpty(s string) bool {
 	return strings.TrimSpace(s) == ""
 }
+
+// NormalizeTitle converts a page title to its canonical form:
+// underscores become spaces, runs of whitespace are collapsed and
+// the first letter is upper-cased (e.g. "barack_obama" -> "Barack obama")
+func NormalizeTitle(s string) string {
+	s = strings.Join(strings.Fields(strings.ReplaceAll(s, "_", " ")), " ")
+	if s == "" {
+		return s
+	}
+
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[size:]
+}